@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/tools"
+)
+
+// apiPrefix is where the REST API is served
+const apiPrefix = "/api/v1"
+
+// APIError is the body of REST API error responses
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// newAPIHandler serves the REST API, a plain JSON view of the MCP tools for
+// clients that don't speak MCP. Requests are validated like tool arguments,
+// so locations and meal types accept nicknames and dates accept relative
+// expressions. Responses carry an ETag and a Last-Modified time, the time
+// the response last changed, so clients can revalidate cheaply. The API is
+// described by an OpenAPI document at openAPIPath.
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPrefix+"/locations", apiLocations)
+	mux.HandleFunc("GET "+apiPrefix+"/menus/{location}", apiMenu)
+	mux.HandleFunc("GET "+apiPrefix+"/menus/{location}/range", apiMenusRange)
+	mux.HandleFunc("GET "+openAPIPath, apiOpenAPI)
+	mux.HandleFunc("GET /api/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "no such endpoint: "+r.URL.Path)
+	})
+	return mux
+}
+
+// apiLocations serves GET /api/v1/locations, like the menu://locations resource
+func apiLocations(w http.ResponseWriter, r *http.Request) {
+	writeAPIResponse(w, r, "locations", LocationsResource{
+		Locations: config.ValidLocations,
+		MealTypes: config.ValidMealTypes,
+	})
+}
+
+// apiMenu serves GET /api/v1/menus/{location}?meal=&date=, like get_menu.
+// meal is required and date defaults to today.
+func apiMenu(w http.ResponseWriter, r *http.Request) {
+	location, mealType, ok := apiMenuParams(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	date := dates.Today()
+	if v := query.Get("date"); v != "" {
+		var err error
+		if date, err = dates.Normalize(v, dates.Now()); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid date: "+err.Error())
+			return
+		}
+	}
+
+	output, err := tools.GetMenu.Call(r.Context(), toolEnv, tools.GetMenuInput{Location: location, Date: date, MealType: mealType})
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeAPIResponse(w, r, fmt.Sprintf("menu|%s|%s|%s", location, date, mealType), output)
+}
+
+// apiMenusRange serves GET /api/v1/menus/{location}/range?meal=&start=&days=,
+// like get_menus_range. meal is required; start defaults to today and days
+// to the configured default.
+func apiMenusRange(w http.ResponseWriter, r *http.Request) {
+	location, mealType, ok := apiMenuParams(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	start := query.Get("start")
+	if start != "" {
+		if _, err := dates.ResolveRange(start, dates.Now()); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid start date: "+err.Error())
+			return
+		}
+	}
+	days := 0
+	if v := query.Get("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days < 1 {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("days must be a positive number, got %q", v))
+			return
+		}
+	}
+
+	output, err := tools.GetMenusRange.Call(r.Context(), toolEnv, tools.GetMenusRangeInput{
+		Location: location, MealType: mealType, Days: days, StartDate: start,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeAPIResponse(w, r, fmt.Sprintf("range|%s|%s|%s|%d|%s", location, mealType, start, days, dates.Today()), output)
+}
+
+// apiMenuParams validates the location path segment (404 if unknown) and
+// the meal query parameter (400 if missing or unknown)
+func apiMenuParams(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	location, err := config.NormalizeLocation(r.PathValue("location"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err.Error())
+		return "", "", false
+	}
+	meal := r.URL.Query().Get("meal")
+	if meal == "" {
+		writeAPIError(w, http.StatusBadRequest, "the meal query parameter is required")
+		return "", "", false
+	}
+	mealType, err := config.NormalizeMealType(meal)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+	if err := initProviders(); err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "Failed to initialize client: "+err.Error())
+		return "", "", false
+	}
+	return location, mealType, true
+}
+
+// writeAPIResponse writes v as JSON with caching headers. The ETag hashes
+// the body, and Last-Modified is when the body for key was first served as
+// it is now. http.ServeContent answers conditional and HEAD requests.
+func writeAPIResponse(w http.ResponseWriter, r *http.Request, key string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	body = append(body, '\n')
+	hash := sha256.Sum256(body)
+
+	now := dates.Now()
+	menuChanges.Prune(now.Add(-feedTrackingWindow))
+	modified := menuChanges.Changed("api|"+key, string(body), now)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(hash[:16])+`"`)
+	// Menus can change at any time, so clients revalidate on every use
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIError{Error: message})
+}
@@ -0,0 +1,157 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/provider"
+	"github.com/bklieger/diningbot/tools"
+)
+
+// apiGet sends a request to the REST API and returns the response recorder
+func apiGet(t *testing.T, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	newAPIHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+// TestAPIErrors tests status codes and JSON bodies of rejected requests
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/api/v1/menus/nowhere?meal=Lunch", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/menus/branner", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/menus/branner?meal=Elevenses", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/menus/branner?meal=Lunch&date=someday", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/menus/branner/range?meal=Lunch&days=0", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/menus/branner/range?meal=Lunch&start=whenever", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/dishes", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/locations", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := apiGet(t, tt.method, tt.path, nil)
+		if rec.Code != tt.code {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.code)
+			continue
+		}
+		if tt.code == http.StatusMethodNotAllowed {
+			continue
+		}
+		var body APIError
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == "" {
+			t.Errorf("%s %s body = %q, want a JSON error", tt.method, tt.path, rec.Body)
+		}
+	}
+}
+
+// TestAPILocations tests the locations endpoint
+func TestAPILocations(t *testing.T) {
+	rec := apiGet(t, http.MethodGet, "/api/v1/locations", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var body LocationsResource
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body, err)
+	}
+	if !reflect.DeepEqual(body.Locations, config.ValidLocations) || !reflect.DeepEqual(body.MealTypes, config.ValidMealTypes) {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+// TestAPIMenu tests a menu and its caching headers
+func TestAPIMenu(t *testing.T) {
+	useStaticMenus(t, dates.Today(), []string{"Pho", "Rice"})
+
+	rec := apiGet(t, http.MethodGet, "/api/v1/menus/Branner%20Dining?meal=lunch", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var menu tools.GetMenuOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &menu); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body, err)
+	}
+	if menu.Location != "Branner Dining" || menu.MealType != "Lunch" || menu.Date != dates.Today() || !reflect.DeepEqual(menu.Items, []string{"Pho", "Rice"}) {
+		t.Errorf("menu = %+v", menu)
+	}
+
+	etag, modified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("missing caching headers: %v", rec.Header())
+	}
+	if rec := apiGet(t, http.MethodGet, "/api/v1/menus/branner?meal=Lunch", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status = %d, want 304", rec.Code)
+	}
+	if rec := apiGet(t, http.MethodGet, "/api/v1/menus/branner?meal=Lunch", map[string]string{"If-Modified-Since": modified}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since status = %d, want 304", rec.Code)
+	}
+
+	// A changed menu gets a new ETag
+	useStaticMenus(t, dates.Today(), []string{"Pho", "Rice", "Tofu"})
+	rec = apiGet(t, http.MethodGet, "/api/v1/menus/branner?meal=Lunch", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("changed menu status = %d, ETag %s", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+// TestAPIMenusRange tests the range endpoint
+func TestAPIMenusRange(t *testing.T) {
+	useStaticMenus(t, dates.Today(), []string{"Pho"})
+
+	rec := apiGet(t, http.MethodGet, "/api/v1/menus/branner/range?meal=Lunch&days=1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var output tools.GetMenusRangeOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &output); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body, err)
+	}
+	if want := map[string][]string{dates.Today(): {"Pho"}}; !reflect.DeepEqual(output.Menus, want) {
+		t.Errorf("menus = %v, want %v", output.Menus, want)
+	}
+}
+
+// downProvider serves Branner Dining but can't fetch any menu
+type downProvider struct{}
+
+func (downProvider) Name() string        { return "down" }
+func (downProvider) Locations() []string { return []string{"Branner Dining"} }
+func (downProvider) MealTypes() []string { return []string{"Lunch"} }
+
+func (downProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	return nil, errors.New("site unreachable")
+}
+
+// TestAPIMenusRangeUnavailable tests that a range whose menus all fail is a
+// 502, not empty menus that clients would cache
+func TestAPIMenusRangeUnavailable(t *testing.T) {
+	registry := provider.NewRegistry()
+	registry.Register(downProvider{})
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	rec := apiGet(t, http.MethodGet, "/api/v1/menus/branner/range?meal=Lunch&days=2", nil)
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Last-Modified") != "" {
+		t.Errorf("a failed range has caching headers: %v", rec.Header())
+	}
+}
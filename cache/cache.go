@@ -53,6 +53,21 @@ func (c *MenuCache) Get(location, date, mealType string) ([]string, bool) {
 	return result, true
 }
 
+// Peek retrieves a cached menu regardless of expiry
+func (c *MenuCache) Peek(location, date, mealType string) ([]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.items[c.key(location, date, mealType)]
+	if !exists {
+		return nil, false
+	}
+
+	result := make([]string, len(entry.Items))
+	copy(result, entry.Items)
+	return result, true
+}
+
 // Set stores a menu result in the cache
 func (c *MenuCache) Set(location, date, mealType string, items []string) {
 	c.mu.Lock()
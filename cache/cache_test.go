@@ -57,6 +57,26 @@ func TestMenuCache_Expiry(t *testing.T) {
 	}
 }
 
+func TestMenuCache_Peek(t *testing.T) {
+	cache := NewMenuCache(50 * time.Millisecond)
+
+	if _, found := cache.Peek("Location1", "1/1/2025", "Lunch"); found {
+		t.Error("Expected Peek miss on empty cache")
+	}
+
+	cache.Set("Location1", "1/1/2025", "Lunch", []string{"Item1"})
+	time.Sleep(80 * time.Millisecond)
+
+	// Get honors the TTL, Peek does not
+	if _, found := cache.Get("Location1", "1/1/2025", "Lunch"); found {
+		t.Error("Expected Get miss after expiry")
+	}
+	items, found := cache.Peek("Location1", "1/1/2025", "Lunch")
+	if !found || len(items) != 1 || items[0] != "Item1" {
+		t.Errorf("Peek() = %v, %v; want [Item1], true", items, found)
+	}
+}
+
 func TestMenuCache_Clear(t *testing.T) {
 	cache := NewMenuCache(1 * time.Hour)
 
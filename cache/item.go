@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// itemEntry is a cached value with the time it was stored
+type itemEntry[V any] struct {
+	value     V
+	timestamp time.Time
+}
+
+// ItemCache provides thread-safe caching of per-item details, like nutrition
+// labels, that are shared by every menu serving the item
+type ItemCache[V any] struct {
+	mu    sync.RWMutex
+	items map[string]itemEntry[V]
+	ttl   time.Duration
+}
+
+// NewItemCache creates a new item cache with the specified TTL
+func NewItemCache[V any](ttl time.Duration) *ItemCache[V] {
+	return &ItemCache[V]{
+		items: make(map[string]itemEntry[V]),
+		ttl:   ttl,
+	}
+}
+
+// Get retrieves a cached value if it exists and hasn't expired
+func (c *ItemCache[V]) Get(key string) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.items[key]
+	if !exists || time.Since(entry.timestamp) > c.ttl {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Set stores a value in the cache
+func (c *ItemCache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = itemEntry[V]{value: value, timestamp: time.Now()}
+}
+
+// Len returns the number of entries, including expired ones
+func (c *ItemCache[V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// CleanExpired removes expired entries from the cache
+func (c *ItemCache[V]) CleanExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range c.items {
+		if now.Sub(entry.timestamp) > c.ttl {
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItemCache_GetSet(t *testing.T) {
+	cache := NewItemCache[int](1 * time.Hour)
+
+	if _, found := cache.Get("oatmeal"); found {
+		t.Error("Expected cache miss, got cache hit")
+	}
+
+	cache.Set("oatmeal", 150)
+	value, found := cache.Get("oatmeal")
+	if !found || value != 150 {
+		t.Errorf("Get() = %d, %v, want 150, true", value, found)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+}
+
+func TestItemCache_Expiry(t *testing.T) {
+	cache := NewItemCache[string](50 * time.Millisecond)
+	cache.Set("toast", "1 slice")
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, found := cache.Get("toast"); found {
+		t.Error("Expected expired entry to miss")
+	}
+	cache.CleanExpired()
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after CleanExpired, want 0", cache.Len())
+	}
+}
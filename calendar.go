@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/ical"
+)
+
+// handleICal serves /ical/{location}.ics, an iCalendar feed with an event
+// per meal served at the location. It takes the same query parameters as
+// the Atom and RSS feeds (see parseMenuFeedRequest).
+func handleICal(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseMenuFeedRequest(w, r, "/ical/", ".ics")
+	if !ok {
+		return
+	}
+
+	// A failed fetch is an error rather than an empty calendar, which
+	// calendar apps would take as every meal being cancelled
+	calendar, err := menuCalendar(req, dates.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	var body bytes.Buffer
+	if err := calendar.Encode(&body); err != nil {
+		http.Error(w, "Error encoding calendar: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", slug(req.location)+".ics"))
+	w.Write(body.Bytes())
+}
+
+// menuCalendar builds a calendar of the meals served at a location. Meals
+// without known service hours become all-day events. It fails if no menu
+// can be fetched.
+func menuCalendar(req menuFeedRequest, now time.Time) (ical.Calendar, error) {
+	meals, err := upcomingMeals(req, now)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	calendar := ical.Calendar{Name: req.location + " Menus", Stamp: now}
+	for _, meal := range meals {
+		calendar.Events = append(calendar.Events, mealEvent(req.location, meal.mealType, meal.day, meal.items))
+	}
+	return calendar, nil
+}
+
+// mealEvent is the calendar event of one meal. Its UID depends only on the
+// date, meal and location, so a changed menu replaces the earlier event.
+func mealEvent(location, mealType string, day time.Time, items []string) ical.Event {
+	event := ical.Event{
+		UID:         mealID(location, mealType, day) + "@diningbot",
+		Summary:     mealType + " at " + location,
+		Description: strings.Join(items, "\n"),
+		Location:    location,
+		Start:       day,
+		AllDay:      true,
+	}
+	if hours, ok := settings.Hours(location, mealType); ok {
+		if start, end, err := hours.Times(day); err == nil {
+			event.Start, event.End, event.AllDay = start, end, false
+		}
+	}
+	return event
+}
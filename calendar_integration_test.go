@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/provider"
+)
+
+// TestICalErrors tests that bad feed URLs are rejected before fetching
+func TestICalErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/ical/nowhere.ics", http.StatusNotFound},
+		{"/ical/branner", http.StatusNotFound},
+		{"/ical/branner/lunch.ics", http.StatusNotFound},
+		{"/ical/branner.ics?meals=Lunch,Elevenses", http.StatusBadRequest},
+		{"/ical/branner.ics?days=0", http.StatusBadRequest},
+		{"/ical/branner.ics?days=week", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handleICal(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.code)
+		}
+	}
+}
+
+// TestICalFeed tests fetching a feed; meals that can't be fetched are left out
+func TestICalFeed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	rec := httptest.NewRecorder()
+	handleICal(rec, httptest.NewRequest(http.MethodGet, "/ical/flomo.ics?meals=lunch&days=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, "X-WR-CALNAME:Florence Moore Dining Menus") {
+		t.Errorf("unexpected feed:\n%s", body)
+	}
+	if strings.Contains(body, "Dinner at") {
+		t.Errorf("feed includes meals that weren't asked for:\n%s", body)
+	}
+}
+
+// TestMealEvent tests event times and UIDs
+func TestMealEvent(t *testing.T) {
+	day := time.Date(2025, 1, 15, 9, 0, 0, 0, dates.Campus)
+
+	lunch := mealEvent("Branner Dining", "Lunch", day, []string{"Pho", "Rice"})
+	if lunch.UID != "20250115-lunch-branner-dining@diningbot" {
+		t.Errorf("UID = %q", lunch.UID)
+	}
+	if lunch.AllDay || lunch.Start.Hour() != 11 || lunch.End.Hour() != 14 || lunch.Start.Day() != 15 {
+		t.Errorf("lunch = %v to %v, want 11:00 to 14:00 on the 15th", lunch.Start, lunch.End)
+	}
+	if lunch.Description != "Pho\nRice" || lunch.Summary != "Lunch at Branner Dining" {
+		t.Errorf("lunch = %+v", lunch)
+	}
+
+	// The UID doesn't depend on the time of day or the menu, so updates replace the event
+	again := mealEvent("Branner Dining", "Lunch", day.Add(5*time.Hour), []string{"Tacos"})
+	if again.UID != lunch.UID {
+		t.Errorf("UID changed from %q to %q", lunch.UID, again.UID)
+	}
+
+	if late := mealEvent("Branner Dining", "Late Night", day, []string{"Pizza"}); !late.AllDay {
+		t.Errorf("a meal without service hours should be an all-day event, got %+v", late)
+	}
+}
+
+// TestICalUnavailable tests that the calendar is a 502 when no menu can be
+// fetched, rather than an empty calendar that would cancel every meal
+func TestICalUnavailable(t *testing.T) {
+	registry := provider.NewRegistry()
+	registry.Register(downProvider{})
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	rec := httptest.NewRecorder()
+	handleICal(rec, httptest.NewRequest(http.MethodGet, "/ical/branner.ics?days=2", nil))
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "site unreachable") {
+		t.Errorf("status = %d %q, want 502 with the fetch error", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,521 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/format"
+	"github.com/bklieger/diningbot/tools"
+	"github.com/bklieger/diningbot/utils"
+)
+
+// Exit codes of the command line interface
+const (
+	exitOK = 0
+	// exitError means a menu couldn't be fetched or the server failed
+	exitError = 1
+	// exitUsage means the arguments were wrong, like an unknown location
+	exitUsage = 2
+	// exitNotFound means the menu was empty or the search found nothing
+	exitNotFound = 3
+)
+
+// Where commands print; replaced in tests
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// command is a CLI subcommand
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string, configPath *string) int
+}
+
+var commands = []command{
+	{"menu", "<location|all> <meal> [--date DATE] [--format FORMAT]", "Print a menu, or a meal at every hall", runMenu},
+	{"range", "<location> <meal> [--start DATE] [--days N] [--format FORMAT]", "Print menus for several days", runRange},
+	{"search", "<dish> [--date DATE] [--days N] [--location L] [--meal M]", "Find a dish on upcoming menus", runSearch},
+	{"locations", "", "List dining halls and meal types", runLocations},
+	{"serve", "[--http ADDR]", "Run the MCP server (the default without a command)", runServe},
+}
+
+// runCLI runs the command named by args and returns the exit code. Without a
+// command the MCP server runs over stdio, as MCP clients expect.
+func runCLI(args []string) int {
+	global := flag.NewFlagSet("diningbot", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	configPath := global.String("config", os.Getenv(config.ConfigPathEnv), "path to a JSON config file (env "+config.ConfigPathEnv+")")
+	global.Usage = func() { printUsage(stderr, global) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	args = global.Args()
+	if len(args) == 0 {
+		return runServe(nil, configPath)
+	}
+	if args[0] == "help" {
+		printUsage(stdout, global)
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], configPath)
+		}
+	}
+	fmt.Fprintf(stderr, "diningbot: unknown command %q\n\n", args[0])
+	printUsage(stderr, global)
+	return exitUsage
+}
+
+// printUsage lists the commands and global flags
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: diningbot [--config FILE] <command> [arguments] [--json]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+		if cmd.args != "" {
+			fmt.Fprintf(w, "  %-10s   diningbot %s %s\n", "", cmd.name, cmd.args)
+		}
+	}
+	fmt.Fprintf(w, "\nExit codes: 0 ok, 1 fetch or server error, 2 bad arguments, 3 nothing found\n\nFlags:\n")
+	global.SetOutput(w)
+	global.PrintDefaults()
+}
+
+// newFlagSet creates a subcommand's flags; every command also accepts --config
+func newFlagSet(name, args string, configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(configPath, "config", *configPath, "path to a JSON config file")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: diningbot %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags given anywhere among args, like
+// `menu Branner Lunch --date tomorrow`, and returns the other arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// checkArgs parses a subcommand's arguments and checks how many were given.
+// It returns false with an exit code if the command should stop.
+func checkArgs(fs *flag.FlagSet, args []string, want int) ([]string, int, bool) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, exitOK, false
+		}
+		return nil, exitUsage, false
+	}
+	if want >= 0 && len(positional) != want {
+		fmt.Fprintf(stderr, "diningbot %s: expected %d arguments, got %d\n", fs.Name(), want, len(positional))
+		fs.Usage()
+		return nil, exitUsage, false
+	}
+	return positional, exitOK, true
+}
+
+// setupCommand loads settings for a command, reporting a failure
+func setupCommand(configPath string, discover bool) (int, bool) {
+	if err := setup(configPath, discover); err != nil {
+		fmt.Fprintf(stderr, "diningbot: %v\n", err)
+		return exitError, false
+	}
+	return exitOK, true
+}
+
+// parseCommand parses a subcommand's arguments and loads settings. It
+// returns false with an exit code if the command should stop. Commands
+// don't build tool schemas, so the menu site isn't searched for new halls.
+func parseCommand(fs *flag.FlagSet, args []string, want int, configPath *string) ([]string, int, bool) {
+	positional, code, ok := checkArgs(fs, args, want)
+	if !ok {
+		return nil, code, false
+	}
+	if code, ok := setupCommand(*configPath, false); !ok {
+		return nil, code, false
+	}
+	return positional, exitOK, true
+}
+
+// usageError reports bad arguments
+func usageError(fs *flag.FlagSet, err error) int {
+	fmt.Fprintf(stderr, "diningbot %s: %v\n", fs.Name(), err)
+	return exitUsage
+}
+
+// toolError reports a failed tool call
+func toolError(err error) int {
+	fmt.Fprintf(stderr, "diningbot: %v\n", err)
+	return exitError
+}
+
+// printJSON writes v as indented JSON
+func printJSON(v any) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(stderr, "diningbot: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// printItems lists menu items, one per line
+func printItems(items []string) {
+	if len(items) == 0 {
+		fmt.Fprintln(stdout, "  (no items)")
+	}
+	for _, item := range items {
+		fmt.Fprintf(stdout, "  %s\n", item)
+	}
+}
+
+// menuFormat picks the output format of the menu commands; --json is short for --format json
+func menuFormat(name string, asJSON bool) (format.Format, error) {
+	if asJSON {
+		return format.JSON, nil
+	}
+	return format.Parse(name)
+}
+
+// printMenus renders menus and returns exitNotFound if none has items
+func printMenus(f format.Format, menus []format.Menu) int {
+	text, err := format.Render(f, menus)
+	if err != nil {
+		fmt.Fprintf(stderr, "diningbot: %v\n", err)
+		return exitError
+	}
+	fmt.Fprint(stdout, text)
+	for _, m := range menus {
+		if len(m.Items) > 0 {
+			return exitOK
+		}
+	}
+	return exitNotFound
+}
+
+// formatUsage documents the --format flag
+var formatUsage = "output format: " + strings.Join(format.Names(), ", ")
+
+// runMenu prints one menu, or one meal at every hall for location "all",
+// using the get_menu tool
+func runMenu(args []string, configPath *string) int {
+	fs := newFlagSet("menu", "<location|all> <meal> [--date DATE] [--format FORMAT]", configPath)
+	date := fs.String("date", "", "date like 1/15/2025, 2025-01-15 or tomorrow (default today)")
+	formatName := fs.String("format", string(format.Text), formatUsage)
+	asJSON := fs.Bool("json", false, "print JSON (same as --format json)")
+	positional, code, ok := parseCommand(fs, args, 2, configPath)
+	if !ok {
+		return code
+	}
+
+	outputFormat, err := menuFormat(*formatName, *asJSON)
+	if err != nil {
+		return usageError(fs, err)
+	}
+	locations := config.ValidLocations
+	if !strings.EqualFold(positional[0], "all") {
+		location, err := config.NormalizeLocation(positional[0])
+		if err != nil {
+			return usageError(fs, err)
+		}
+		locations = []string{location}
+	}
+	mealType, err := config.NormalizeMealType(positional[1])
+	if err != nil {
+		return usageError(fs, err)
+	}
+	if *date != "" {
+		if _, err := dates.Normalize(*date, dates.Now()); err != nil {
+			return usageError(fs, err)
+		}
+	}
+
+	// Halls that fail are reported and left out, unless every hall fails
+	menus := []format.Menu{}
+	for _, location := range locations {
+		output, err := tools.GetMenu.Call(context.Background(), toolEnv, tools.GetMenuInput{Location: location, Date: *date, MealType: mealType})
+		if err != nil {
+			toolError(err)
+			continue
+		}
+		menus = append(menus, format.Menu{Location: output.Location, Date: output.Date, MealType: output.MealType, Items: output.Items})
+	}
+	if len(menus) == 0 {
+		return exitError
+	}
+	return printMenus(outputFormat, menus)
+}
+
+// runRange prints menus for several days, using the get_menus_range tool
+func runRange(args []string, configPath *string) int {
+	fs := newFlagSet("range", "<location> <meal> [--start DATE] [--days N] [--format FORMAT]", configPath)
+	start := fs.String("start", "", "first date, or a range like \"this weekend\" (default today)")
+	days := fs.Int("days", 0, "number of days (default 7)")
+	formatName := fs.String("format", string(format.Text), formatUsage)
+	asJSON := fs.Bool("json", false, "print JSON (same as --format json)")
+	positional, code, ok := parseCommand(fs, args, 2, configPath)
+	if !ok {
+		return code
+	}
+
+	outputFormat, err := menuFormat(*formatName, *asJSON)
+	if err != nil {
+		return usageError(fs, err)
+	}
+	location, err := config.NormalizeLocation(positional[0])
+	if err != nil {
+		return usageError(fs, err)
+	}
+	mealType, err := config.NormalizeMealType(positional[1])
+	if err != nil {
+		return usageError(fs, err)
+	}
+	if *start != "" {
+		if _, err := dates.ResolveRange(*start, dates.Now()); err != nil {
+			return usageError(fs, err)
+		}
+	}
+	if *days < 0 {
+		return usageError(fs, fmt.Errorf("--days must not be negative"))
+	}
+
+	output, err := tools.GetMenusRange.Call(context.Background(), toolEnv, tools.GetMenusRangeInput{
+		Location: location, MealType: mealType, Days: *days, StartDate: *start,
+	})
+	if err != nil {
+		return toolError(err)
+	}
+	return printMenus(outputFormat, format.FromRange(output.Location, output.MealType, output.Menus))
+}
+
+// SearchMatch is a menu serving a searched dish
+type SearchMatch struct {
+	Date     string `json:"date"`
+	Location string `json:"location"`
+	MealType string `json:"mealType"`
+	Item     string `json:"item"`
+}
+
+// SearchResult is the JSON output of the search command
+type SearchResult struct {
+	Query   string        `json:"query"`
+	Matches []SearchMatch `json:"matches"`
+	// Errors are menus that couldn't be fetched
+	Errors []string `json:"errors"`
+}
+
+// listFlag is a flag that may be repeated or given comma-separated values
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// runSearch finds a dish, matched case-insensitively as a substring like
+// watch_dish, on the menus of the given days, halls and meals
+func runSearch(args []string, configPath *string) int {
+	fs := newFlagSet("search", "<dish> [--date DATE] [--days N] [--location L] [--meal M] [--json]", configPath)
+	date := fs.String("date", "", "first date, or a range like \"this weekend\" (default today)")
+	days := fs.Int("days", 0, "number of days to search (default 1, or the length of a --date range)")
+	var locations, mealTypes listFlag
+	fs.Var(&locations, "location", "dining hall to search; repeat or separate with commas (default all)")
+	fs.Var(&mealTypes, "meal", "meal type to search; repeat or separate with commas (default all)")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, code, ok := parseCommand(fs, args, -1, configPath)
+	if !ok {
+		return code
+	}
+	query := strings.TrimSpace(strings.Join(positional, " "))
+	if query == "" {
+		return usageError(fs, fmt.Errorf("a dish to search for is required"))
+	}
+
+	span := dates.Range{Start: dates.Now(), Days: 1}
+	if *date != "" {
+		var err error
+		if span, err = dates.ResolveRange(*date, dates.Now()); err != nil {
+			return usageError(fs, err)
+		}
+	}
+	if *days < 0 {
+		return usageError(fs, fmt.Errorf("--days must not be negative"))
+	}
+	if *days > 0 {
+		span.Days = *days
+	}
+	span.Days = min(max(span.Days, 1), settings.Server.MaxRangeDays)
+
+	if len(locations) == 0 {
+		locations = slices.Clone(config.ValidLocations)
+	}
+	for i, l := range locations {
+		location, err := config.NormalizeLocation(l)
+		if err != nil {
+			return usageError(fs, err)
+		}
+		locations[i] = location
+	}
+	if len(mealTypes) == 0 {
+		mealTypes = slices.Clone(config.ValidMealTypes)
+	}
+	for i, m := range mealTypes {
+		mealType, err := config.NormalizeMealType(m)
+		if err != nil {
+			return usageError(fs, err)
+		}
+		mealTypes[i] = mealType
+	}
+
+	// Skip dates a hall's site doesn't offer, as get_menus_range does; if its
+	// date list is unavailable, try every day
+	offered := make(map[string]map[string]bool, len(locations))
+	for _, location := range locations {
+		if available, err := menuProviders.AvailableDates(location); err == nil {
+			offered[location] = make(map[string]bool, len(available))
+			for _, d := range available {
+				offered[location][d] = true
+			}
+		}
+	}
+
+	result := SearchResult{Query: query, Matches: []SearchMatch{}, Errors: []string{}}
+	needle := strings.ToLower(query)
+	fetched := 0
+	for i := range span.Days {
+		day := utils.FormatDate(span.Start.AddDate(0, 0, i))
+		for _, location := range locations {
+			if listed, ok := offered[location]; ok && !listed[day] {
+				continue
+			}
+			for _, mealType := range mealTypes {
+				items, err := menuProviders.GetMenu(location, day, mealType)
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s %s %s: %v", location, mealType, day, err))
+					continue
+				}
+				fetched++
+				for _, item := range items {
+					if strings.Contains(strings.ToLower(item), needle) {
+						result.Matches = append(result.Matches, SearchMatch{Date: day, Location: location, MealType: mealType, Item: item})
+					}
+				}
+			}
+		}
+	}
+
+	if *asJSON {
+		if code := printJSON(result); code != exitOK {
+			return code
+		}
+	} else {
+		for _, e := range result.Errors {
+			fmt.Fprintf(stderr, "diningbot: %s\n", e)
+		}
+		for _, m := range result.Matches {
+			fmt.Fprintf(stdout, "%s  %s  %s  %s\n", m.Date, m.Location, m.MealType, m.Item)
+		}
+		if len(result.Matches) == 0 {
+			fmt.Fprintf(stdout, "No menus list %q\n", query)
+		}
+	}
+	switch {
+	case fetched == 0 && len(result.Errors) > 0:
+		return exitError
+	case len(result.Matches) == 0:
+		return exitNotFound
+	}
+	return exitOK
+}
+
+// runLocations lists the dining halls and meal types, as the menu://locations resource does
+func runLocations(args []string, configPath *string) int {
+	fs := newFlagSet("locations", "[--json]", configPath)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, code, ok := parseCommand(fs, args, 0, configPath); !ok {
+		return code
+	}
+
+	locations := LocationsResource{Locations: config.ValidLocations, MealTypes: config.ValidMealTypes}
+	if *asJSON {
+		return printJSON(locations)
+	}
+	fmt.Fprintln(stdout, "Locations:")
+	printItems(locations.Locations)
+	fmt.Fprintln(stdout, "Meal types:")
+	printItems(locations.MealTypes)
+	return exitOK
+}
+
+// runServe runs the MCP server, over HTTP if --http or PORT is set and stdio otherwise
+func runServe(args []string, configPath *string) int {
+	fs := newFlagSet("serve", "[--http ADDR]", configPath)
+	httpAddr := fs.String("http", "", "serve Streamable HTTP on ADDR, like :8080 or 0.0.0.0:8080 (overrides PORT and BIND_ADDR)")
+	if _, code, ok := checkArgs(fs, args, 0); !ok {
+		return code
+	}
+
+	var host, port string
+	if *httpAddr != "" {
+		var err error
+		host, port, err = net.SplitHostPort(*httpAddr)
+		if err != nil {
+			return usageError(fs, fmt.Errorf("invalid --http address: %w", err))
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return usageError(fs, fmt.Errorf("invalid --http port %q", port))
+		}
+	}
+
+	// The server builds tool schemas, so it looks for new halls on the menu site
+	if code, ok := setupCommand(*configPath, true); !ok {
+		return code
+	}
+	if port != "" {
+		// Without a host, keep the configured bind address (localhost by default)
+		if host != "" {
+			settings.Server.BindAddr = host
+		}
+		settings.Server.Port = port
+	}
+	return serve()
+}
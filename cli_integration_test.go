@@ -0,0 +1,195 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/provider"
+	"github.com/bklieger/diningbot/tools"
+)
+
+// runBinary runs the built binary with args and returns its stdout, stderr and exit code
+func runBinary(t *testing.T, binaryPath string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(binaryPath, args...)
+	cmd.Env = append(os.Environ(), "DISCOVER_SITE_OPTIONS=false")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("Failed to run diningbot: %v", err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+// TestCLILocations tests the locations command's JSON output
+func TestCLILocations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	stdout, stderr, code := runBinary(t, buildBinary(t), "locations", "--json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, exitOK, stderr)
+	}
+	var locations LocationsResource
+	if err := json.Unmarshal([]byte(stdout), &locations); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", stdout, err)
+	}
+	if len(locations.Locations) == 0 || len(locations.MealTypes) == 0 {
+		t.Errorf("locations = %+v, want locations and meal types", locations)
+	}
+}
+
+// TestCLISkipsDiscovery tests that commands which don't build tool schemas
+// don't wait for the menu site to list its halls
+func TestCLISkipsDiscovery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+	}))
+	defer site.Close()
+	defer close(release)
+
+	cmd := exec.Command(buildBinary(t), "locations")
+	cmd.Env = append(os.Environ(), "DISCOVER_SITE_OPTIONS=true", "DININGBOT_BASE_URL="+site.URL+"/")
+	start := time.Now()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("locations failed: %v (%s)", err, out)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("locations took %v, want it not to wait for the site", elapsed)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("locations made %d requests to the site, want none", n)
+	}
+}
+
+// TestCLIUsageErrors tests that bad arguments exit with the usage code before fetching anything
+func TestCLIUsageErrors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown command", []string{"breakfast"}, "unknown command"},
+		{"unknown location", []string{"menu", "Nowhere Dining", "Lunch"}, "invalid location"},
+		{"unknown meal type", []string{"menu", "Branner Dining", "Elevenses"}, "invalid meal type"},
+		{"invalid date", []string{"menu", "Branner Dining", "Lunch", "--date", "someday"}, "someday"},
+		{"unknown format", []string{"range", "Branner Dining", "Lunch", "--format", "yaml"}, "unknown format"},
+		{"missing arguments", []string{"range", "Branner Dining"}, "expected 2 arguments"},
+		{"missing dish", []string{"search", "--location", "Branner Dining"}, "dish"},
+		{"invalid address", []string{"serve", "--http", "8080"}, "--http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, code := runBinary(t, binaryPath, tt.args...)
+			if code != exitUsage {
+				t.Errorf("exit code = %d, want %d", code, exitUsage)
+			}
+			if !strings.Contains(strings.ToLower(stderr), strings.ToLower(tt.want)) {
+				t.Errorf("stderr = %q, want it to mention %q", stderr, tt.want)
+			}
+		})
+	}
+}
+
+// TestCLIMenu tests the menu command against the live site
+func TestCLIMenu(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	stdout, stderr, code := runBinary(t, buildBinary(t), "menu", "Arrillaga Family Dining Commons", "Lunch", "--json")
+	// A closed hall exits with exitNotFound but still prints the menu
+	if code != exitOK && code != exitNotFound {
+		t.Fatalf("exit code = %d (stderr: %s)", code, stderr)
+	}
+	var output tools.GetMenuOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", stdout, err)
+	}
+	if output.Location != "Arrillaga Family Dining Commons" || output.MealType != "Lunch" {
+		t.Errorf("output = %+v", output)
+	}
+	if (code == exitOK) != (len(output.Items) > 0) {
+		t.Errorf("exit code %d with %d items", code, len(output.Items))
+	}
+}
+
+// listingProvider serves Branner Dining lunches on the dates it lists and
+// records which dates were fetched
+type listingProvider struct {
+	listed  []string
+	fetched []string
+}
+
+func (p *listingProvider) Name() string        { return "listing" }
+func (p *listingProvider) Locations() []string { return []string{"Branner Dining"} }
+func (p *listingProvider) MealTypes() []string { return []string{"Lunch"} }
+
+func (p *listingProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	p.fetched = append(p.fetched, date)
+	return []string{"Pho"}, nil
+}
+
+func (p *listingProvider) AvailableDates(location string) ([]string, error) {
+	return p.listed, nil
+}
+
+// TestCLISearchSkipsUnlistedDates tests that search only fetches the dates
+// the site lists, as get_menus_range does
+func TestCLISearchSkipsUnlistedDates(t *testing.T) {
+	listing := &listingProvider{listed: []string{"1/16/2025"}}
+	registry := provider.NewRegistry()
+	registry.Register(listing)
+	savedProviders, savedSettings, savedStdout := menuProviders, settings, stdout
+	menuProviders = registry
+	var out bytes.Buffer
+	stdout = &out
+	t.Cleanup(func() { menuProviders, settings, stdout = savedProviders, savedSettings, savedStdout })
+
+	configPath := ""
+	code := runSearch([]string{"pho", "--date", "1/15/2025", "--days", "3", "--location", "Branner Dining", "--json"}, &configPath)
+	if code != exitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !slices.Equal(listing.fetched, []string{"1/16/2025"}) {
+		t.Errorf("fetched %v, want only the listed 1/16/2025", listing.fetched)
+	}
+	var result SearchResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", out.String(), err)
+	}
+	if len(result.Matches) != 1 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -1,48 +1,140 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bklieger/diningbot/cache"
 	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/health"
 	"github.com/bklieger/diningbot/parser"
+	"github.com/bklieger/diningbot/utils"
 	"golang.org/x/net/publicsuffix"
 )
 
+// Form fields of the menu page's dropdowns
+const (
+	locationsField = "ctl00$MainContent$lstLocations"
+	dayField       = "ctl00$MainContent$lstDay"
+	mealTypeField  = "ctl00$MainContent$lstMealType"
+)
+
+// maxNutritionFetches bounds how many nutrition labels are fetched at once
+const maxNutritionFetches = 4
+
+// SiteOptions are the choices offered by the menu page's dropdowns
+type SiteOptions struct {
+	Locations []parser.Option `json:"locations"`
+	MealTypes []parser.Option `json:"mealTypes"`
+}
+
+// availableDates are the dates the menu page listed for a location
+type availableDates struct {
+	dates   []string
+	fetched time.Time
+}
+
 type DiningHallClient struct {
+	mu                 sync.Mutex // serializes requests sharing the ASP.NET session state
 	client             *http.Client
 	jar                *cookiejar.Jar
 	baseURL            string
+	userAgent          string
+	datesTTL           time.Duration // how long a location's listed dates are trusted
+	minInterval        time.Duration // minimum gap between requests to the site
+	rateMu             sync.Mutex    // guards lastRequest, since label fetches don't hold mu
+	lastRequest        time.Time
 	viewState          string
 	eventValidation    string
 	viewStateGenerator string
-	Debug              bool // Enable debug output
+	options            SiteOptions               // dropdown choices from the last page load
+	availableDates     map[string]availableDates // keyed by location API value
+	Debug              bool                      // Enable debug output
 	cache              *cache.MenuCache
+	rules              *parser.RuleSet
+	monitor            *health.ParserMonitor
+	menuItems          *cache.ItemCache[[]parser.Item] // structured items of fetched menus, keyed by menuKey
+	nutrition          *cache.ItemCache[*parser.Nutrition]
+	fetchNutrition     bool // read nutrition labels for whole menus in MenuItems
+}
+
+// Config configures a DiningHallClient
+type Config struct {
+	BaseURL           string
+	UserAgent         string
+	Timeout           time.Duration
+	CacheTTL          time.Duration
+	AvailableDatesTTL time.Duration
+	// MinRequestInterval rate-limits requests to the site; zero disables the limit
+	MinRequestInterval time.Duration
+	// ParseRules replace the default menu extraction rules when set
+	ParseRules []parser.Rule
+	// Monitor, if set, is told about every menu page parsed
+	Monitor *health.ParserMonitor
+	// FetchNutrition makes MenuItems follow every item's link to its nutrition label
+	FetchNutrition bool
+	// NutritionTTL is how long an item's nutrition label is reused
+	NutritionTTL time.Duration
+}
+
+// DefaultConfig returns the configuration used by NewDiningHallClient
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:           config.DefaultBaseURL,
+		UserAgent:         config.DefaultUserAgent,
+		Timeout:           config.DefaultHTTPTimeout,
+		CacheTTL:          config.DefaultCacheTTL,
+		AvailableDatesTTL: config.DefaultAvailableDatesTTL,
+		NutritionTTL:      config.DefaultNutritionTTL,
+	}
 }
 
 func NewDiningHallClient() (*DiningHallClient, error) {
+	return NewDiningHallClientWithConfig(DefaultConfig())
+}
+
+// NewDiningHallClientWithConfig creates a client with explicit settings
+func NewDiningHallClientWithConfig(cfg Config) (*DiningHallClient, error) {
 	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	if err != nil {
 		return nil, err
 	}
 
+	rules := parser.DefaultRuleSet()
+	if len(cfg.ParseRules) > 0 {
+		if rules, err = parser.NewRuleSet(cfg.ParseRules); err != nil {
+			return nil, fmt.Errorf("invalid parse rules: %w", err)
+		}
+	}
+
 	client := &http.Client{
 		Jar:     jar,
-		Timeout: 30 * time.Second,
+		Timeout: cfg.Timeout,
 	}
 
 	return &DiningHallClient{
-		client:  client,
-		jar:     jar,
-		baseURL: config.DefaultBaseURL,
-		cache:   cache.NewMenuCache(1 * time.Hour), // 1 hour cache TTL
+		client:         client,
+		jar:            jar,
+		baseURL:        cfg.BaseURL,
+		userAgent:      cfg.UserAgent,
+		datesTTL:       cfg.AvailableDatesTTL,
+		minInterval:    cfg.MinRequestInterval,
+		cache:          cache.NewMenuCache(cfg.CacheTTL),
+		availableDates: make(map[string]availableDates),
+		rules:          rules,
+		monitor:        cfg.Monitor,
+		menuItems:      cache.NewItemCache[[]parser.Item](cfg.CacheTTL),
+		nutrition:      cache.NewItemCache[*parser.Nutrition](cfg.NutritionTTL),
+		fetchNutrition: cfg.FetchNutrition,
 	}, nil
 }
 
@@ -50,18 +142,18 @@ func (d *DiningHallClient) SetBaseURL(url string) {
 	d.baseURL = url
 }
 
-func (d *DiningHallClient) initializeSession() error {
+func (d *DiningHallClient) initializeSession(ctx context.Context) error {
 	// Load Menu.aspx to get the form's ViewState
 	menuURL := strings.TrimSuffix(d.baseURL, "/") + "/Menu.aspx"
-	req, err := http.NewRequest("GET", menuURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", menuURL, nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36")
+	req.Header.Set("User-Agent", d.userAgent)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	resp, err := d.client.Do(req)
+	resp, err := d.do(req)
 	if err != nil {
 		return err
 	}
@@ -95,10 +187,19 @@ func (d *DiningHallClient) initializeSession() error {
 	d.eventValidation = parser.ExtractEventValidation(htmlContent)
 	d.viewStateGenerator = parser.ExtractViewStateGenerator(htmlContent)
 
+	// Record the dropdown choices so callers can discover new halls and meals
+	if locations := parser.ExtractSelectOptions(htmlContent, locationsField); len(locations) > 0 {
+		d.options.Locations = locations
+	}
+	if mealTypes := parser.ExtractSelectOptions(htmlContent, mealTypeField); len(mealTypes) > 0 {
+		d.options.MealTypes = mealTypes
+	}
+
 	if d.Debug {
 		fmt.Printf("DEBUG: Extracted ViewState length: %d\n", len(d.viewState))
 		fmt.Printf("DEBUG: Extracted EventValidation length: %d\n", len(d.eventValidation))
 		fmt.Printf("DEBUG: Extracted ViewStateGenerator: %s\n", d.viewStateGenerator)
+		fmt.Printf("DEBUG: Extracted %d locations and %d meal types\n", len(d.options.Locations), len(d.options.MealTypes))
 		if len(d.viewState) == 0 {
 			// Show a snippet to debug why extraction failed
 			if len(htmlContent) > 500 {
@@ -112,17 +213,17 @@ func (d *DiningHallClient) initializeSession() error {
 	return nil
 }
 
-func (d *DiningHallClient) renewSession() error {
+func (d *DiningHallClient) renewSession(ctx context.Context) error {
 	renewURL := strings.TrimSuffix(d.baseURL, "/") + "/RenewSession.aspx"
-	req, err := http.NewRequest("GET", renewURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", renewURL, nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36")
+	req.Header.Set("User-Agent", d.userAgent)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	resp, err := d.client.Do(req)
+	resp, err := d.do(req)
 	if err != nil {
 		return err
 	}
@@ -141,14 +242,19 @@ func (d *DiningHallClient) renewSession() error {
 
 // GetMenu fetches the menu for a given location, date, and meal type
 func (d *DiningHallClient) GetMenu(location, date, mealType string) ([]string, error) {
-	// Validate inputs
-	if !config.IsValidLocation(location) {
-		return nil, fmt.Errorf("invalid location: %s", location)
+	// Validate inputs, resolving nicknames like "FloMo" to display names
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, err
 	}
-	if !config.IsValidMealType(mealType) {
-		return nil, fmt.Errorf("invalid meal type: %s", mealType)
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return nil, err
 	}
 
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	// Check cache first
 	if cached, found := d.cache.Get(location, date, mealType); found {
 		if d.Debug {
@@ -161,91 +267,370 @@ func (d *DiningHallClient) GetMenu(location, date, mealType string) ([]string, e
 		fmt.Printf("DEBUG: Cache miss for %s %s %s, fetching from server\n", location, date, mealType)
 	}
 
-	// Initialize session if not already done
-	if d.viewState == "" {
-		if err := d.initializeSession(); err != nil {
-			return nil, fmt.Errorf("failed to initialize session: %w", err)
+	foods, err := d.fetchMenu(context.Background(), location, date, mealType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store in cache (even if empty, to avoid repeated failed requests)
+	d.cache.Set(location, date, mealType, foods)
+
+	return foods, nil
+}
+
+// RefreshMenu fetches a menu from the server bypassing the cache, stores it,
+// and reports whether it differs from the previously cached item list.
+// A menu that was never cached is not reported as changed.
+func (d *DiningHallClient) RefreshMenu(location, date, mealType string) ([]string, bool, error) {
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, false, err
+	}
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	previous, hadPrevious := d.cache.Peek(location, date, mealType)
+
+	foods, err := d.fetchMenu(context.Background(), location, date, mealType)
+	if err != nil {
+		return nil, false, err
+	}
+	d.cache.Set(location, date, mealType, foods)
+
+	changed := hadPrevious && !slices.Equal(previous, foods)
+	if d.Debug && changed {
+		fmt.Printf("DEBUG: Menu changed for %s %s %s\n", location, date, mealType)
+	}
+	return foods, changed, nil
+}
+
+// MenuItems returns a menu's items with their ingredients, allergens and
+// detail links, fetching the menu like GetMenu. Nutrition labels are read
+// too if the client was configured with FetchNutrition; a label that can't
+// be fetched leaves its item without nutrition rather than failing the menu.
+// Labels are fetched a few at a time without holding d.mu, so other menu
+// requests aren't held up behind them.
+func (d *DiningHallClient) MenuItems(location, date, mealType string) ([]parser.Item, error) {
+	items, err := d.structuredMenu(location, date, mealType)
+	if err != nil || !d.fetchNutrition {
+		return items, err
+	}
+	d.FillNutrition(context.Background(), items, len(items))
+	return items, nil
+}
+
+// FillNutrition sets the nutrition labels of items that have none, using
+// cached labels and fetching at most limit others, a few at a time. It stops
+// fetching when ctx is done. It returns how many labels were fetched and how
+// many items were skipped because of the limit or ctx; a label that can't be
+// fetched leaves its item without nutrition.
+func (d *DiningHallClient) FillNutrition(ctx context.Context, items []parser.Item, limit int) (fetched, skipped int) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxNutritionFetches)
+	for i := range items {
+		if items[i].Nutrition != nil {
+			continue
+		}
+		if nutrition, found := d.nutrition.Get(strings.ToLower(items[i].Name)); found {
+			items[i].Nutrition = nutrition
+			continue
 		}
-		// Renew session to ensure it's active
-		if err := d.renewSession(); err != nil {
-			return nil, fmt.Errorf("failed to renew session: %w", err)
+		if _, ok := d.resolveLink(items[i].Link); !ok {
+			continue
 		}
-		// Re-fetch the main page to get updated ViewState after session renewal
-		if err := d.initializeSession(); err != nil {
-			return nil, fmt.Errorf("failed to re-initialize session: %w", err)
+		if fetched >= limit || ctx.Err() != nil {
+			skipped++
+			continue
 		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			skipped++
+			continue
+		}
+		fetched++
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			nutrition, err := d.itemNutrition(ctx, items[i])
+			if err != nil {
+				if d.Debug {
+					fmt.Printf("DEBUG: Failed to fetch nutrition for %s: %v\n", items[i].Name, err)
+				}
+				return
+			}
+			items[i].Nutrition = nutrition
+		}()
 	}
+	wg.Wait()
+	return fetched, skipped
+}
 
-	// POST to Menu.aspx, not the base URL
-	menuURL := strings.TrimSuffix(d.baseURL, "/") + "/Menu.aspx"
-
-	// Prepare form data
-	formData := url.Values{}
-	formData.Set("__EVENTTARGET", "GetMenulstDay")
-	formData.Set("__EVENTARGUMENT", "")
-	formData.Set("__VIEWSTATE", d.viewState)
-	formData.Set("__VIEWSTATEGENERATOR", d.viewStateGenerator)
-	formData.Set("__EVENTVALIDATION", d.eventValidation)
-	formData.Set("ctl00$MainContent$lstLocations", config.GetLocationValue(location))
-	formData.Set("ctl00$MainContent$lstDay", date)
-	formData.Set("ctl00$MainContent$lstMealType", mealType)
+// ItemDetails returns one item of a menu, matched by name ignoring case,
+// with its nutrition label if the item links to one
+func (d *DiningHallClient) ItemDetails(location, date, mealType, name string) (parser.Item, error) {
+	items, err := d.structuredMenu(location, date, mealType)
+	if err != nil {
+		return parser.Item{}, err
+	}
+	item, found := parser.FindItem(items, name)
+	if !found {
+		return parser.Item{}, fmt.Errorf("%q is not on the %s menu at %s on %s", name, mealType, location, date)
+	}
 
-	if d.Debug {
-		fmt.Printf("DEBUG: Posting to %s\n", menuURL)
-		fmt.Printf("DEBUG: Form data: %v\n", formData)
+	if item.Nutrition, err = d.itemNutrition(context.Background(), item); err != nil {
+		return item, fmt.Errorf("failed to fetch nutrition for %s: %w", item.Name, err)
 	}
+	return item, nil
+}
 
-	req, err := http.NewRequest("POST", menuURL, strings.NewReader(formData.Encode()))
+// structuredMenu fetches a menu like GetMenu and returns a copy of its items
+func (d *DiningHallClient) structuredMenu(location, date, mealType string) ([]parser.Item, error) {
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return nil, err
+	}
+	names, err := d.GetMenu(location, date, mealType)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Origin", "https://rdeapps.stanford.edu")
-	req.Header.Set("Referer", d.baseURL)
+	if items, found := d.menuItems.Get(menuKey(location, date, mealType)); found {
+		return slices.Clone(items), nil
+	}
+	// The menu was cached before its items were recorded; names are all we know
+	items := make([]parser.Item, len(names))
+	for i, name := range names {
+		items[i] = parser.Item{Name: name}
+	}
+	return items, nil
+}
 
-	// Set cookies
-	for _, cookie := range d.jar.Cookies(req.URL) {
-		req.AddCookie(cookie)
+// itemNutrition returns an item's nutrition label, fetching it from the
+// item's link unless it is cached. Labels are cached by item name, since a
+// dish served at several halls or on several days has one label. Items
+// without a followable link have no label. Label pages don't use the
+// session's form state, so callers need not hold d.mu. The fetch is
+// abandoned when ctx is done.
+func (d *DiningHallClient) itemNutrition(ctx context.Context, item parser.Item) (*parser.Nutrition, error) {
+	key := strings.ToLower(item.Name)
+	if nutrition, found := d.nutrition.Get(key); found {
+		return nutrition, nil
+	}
+	labelURL, ok := d.resolveLink(item.Link)
+	if !ok {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", labelURL, nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("User-Agent", d.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Referer", d.baseURL)
 
-	resp, err := d.client.Do(req)
+	resp, err := d.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 
+	// Pages without a label are cached too, so they aren't fetched again
+	var nutrition *parser.Nutrition
+	if label, found := parser.ParseNutrition(string(body)); found {
+		nutrition = &label
+	}
+	d.nutrition.Set(key, nutrition)
+	d.nutrition.CleanExpired()
 	if d.Debug {
-		fmt.Printf("DEBUG: Response status: %d %s\n", resp.StatusCode, resp.Status)
-		fmt.Printf("DEBUG: Response headers:\n")
-		for key, values := range resp.Header {
-			fmt.Printf("DEBUG:   %s: %v\n", key, values)
-		}
-		fmt.Printf("DEBUG: Cookies received:\n")
-		cookies := d.jar.Cookies(req.URL)
-		for _, cookie := range cookies {
-			fmt.Printf("DEBUG:   %s=%s\n", cookie.Name, cookie.Value)
-		}
+		fmt.Printf("DEBUG: Fetched nutrition for %s from %s (label found: %v)\n", item.Name, labelURL, nutrition != nil)
+	}
+	return nutrition, nil
+}
+
+// resolveLink resolves an item link against the menu page, reporting false
+// for links that can't be fetched, like empty or javascript: links
+func (d *DiningHallClient) resolveLink(link string) (string, bool) {
+	if link == "" {
+		return "", false
+	}
+	base, err := url.Parse(strings.TrimSuffix(d.baseURL, "/") + "/Menu.aspx")
+	if err != nil {
+		return "", false
 	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return "", false
+	}
+	return resolved.String(), true
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		if d.Debug {
-			fmt.Printf("DEBUG: Error response body: %s\n", string(body))
+// menuKey identifies a menu in menuItems
+func menuKey(location, date, mealType string) string {
+	return location + "|" + date + "|" + mealType
+}
+
+// Name identifies the client as the Stanford menu provider
+func (d *DiningHallClient) Name() string {
+	return "stanford"
+}
+
+// Locations returns the configured dining hall display names
+func (d *DiningHallClient) Locations() []string {
+	return slices.Clone(config.ValidLocations)
+}
+
+// MealTypes returns the configured meal types
+func (d *DiningHallClient) MealTypes() []string {
+	return slices.Clone(config.ValidMealTypes)
+}
+
+// Options returns the location and meal type choices listed on the menu page,
+// loading the page first if no session has been established yet. Loading
+// stops when ctx is done.
+func (d *DiningHallClient) Options(ctx context.Context) (SiteOptions, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensureSession(ctx); err != nil {
+		return SiteOptions{}, err
+	}
+	return SiteOptions{
+		Locations: slices.Clone(d.options.Locations),
+		MealTypes: slices.Clone(d.options.MealTypes),
+	}, nil
+}
+
+// AvailableDates returns the dates (M/D/YYYY) the menu page's day dropdown offers
+// for a location, in page order. Dates are reused for the configured
+// AvailableDatesTTL, and every menu fetch for the location refreshes them.
+func (d *DiningHallClient) AvailableDates(location string) ([]string, error) {
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	value := config.GetLocationValue(location)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if cached, found := d.availableDates[value]; found && time.Since(cached.fetched) < d.datesTTL {
+		return slices.Clone(cached.dates), nil
+	}
+
+	if err := d.ensureSession(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// Selecting a location posts back and reloads its day dropdown
+	formData := url.Values{}
+	formData.Set("__EVENTTARGET", locationsField)
+	formData.Set(locationsField, value)
+	body, err := d.postMenuForm(context.Background(), formData)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.recordAvailableDates(value, string(body)) {
+		return nil, fmt.Errorf("menu page lists no dates for %s", location)
+	}
+	return slices.Clone(d.availableDates[value].dates), nil
+}
+
+// recordAvailableDates stores the day dropdown of a page for a location,
+// reporting whether the page listed any dates; callers must hold d.mu
+func (d *DiningHallClient) recordAvailableDates(locationValue, htmlContent string) bool {
+	options := parser.ExtractSelectOptions(htmlContent, dayField)
+	if len(options) == 0 {
+		return false
+	}
+
+	dates := make([]string, 0, len(options))
+	for _, o := range options {
+		// Normalize to M/D/YYYY so dates compare equal to the ones we post
+		if t, err := time.Parse("1/2/2006", o.Value); err == nil {
+			dates = append(dates, utils.FormatDate(t))
+		} else {
+			dates = append(dates, o.Value)
 		}
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
+	if d.availableDates == nil {
+		d.availableDates = make(map[string]availableDates)
+	}
+	d.availableDates[locationValue] = availableDates{dates: dates, fetched: time.Now()}
+	if d.Debug {
+		fmt.Printf("DEBUG: %s lists %d dates\n", locationValue, len(dates))
+	}
+	return true
+}
 
-	body, err := io.ReadAll(resp.Body)
+// ensureSession initializes the ASP.NET session if not already done; callers must hold d.mu
+func (d *DiningHallClient) ensureSession(ctx context.Context) error {
+	if d.viewState != "" {
+		return nil
+	}
+	if err := d.initializeSession(ctx); err != nil {
+		return fmt.Errorf("failed to initialize session: %w", err)
+	}
+	// Renew session to ensure it's active. A session left half set up, as when
+	// ctx ends, is started over on the next call.
+	if err := d.renewSession(ctx); err != nil {
+		d.viewState = ""
+		return fmt.Errorf("failed to renew session: %w", err)
+	}
+	// Re-fetch the main page to get updated ViewState after session renewal
+	if err := d.initializeSession(ctx); err != nil {
+		d.viewState = ""
+		return fmt.Errorf("failed to re-initialize session: %w", err)
+	}
+	return nil
+}
+
+// fetchMenu posts the menu form and parses the response; callers must hold d.mu
+func (d *DiningHallClient) fetchMenu(ctx context.Context, location, date, mealType string) ([]string, error) {
+	if err := d.ensureSession(ctx); err != nil {
+		return nil, err
+	}
+
+	// Prepare form data
+	formData := url.Values{}
+	formData.Set("__EVENTTARGET", "GetMenulstDay")
+	formData.Set(locationsField, config.GetLocationValue(location))
+	formData.Set(dayField, date)
+	formData.Set(mealTypeField, mealType)
+
+	body, err := d.postMenuForm(ctx, formData)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update ViewState and EventValidation from response
-	d.viewState = parser.ExtractViewState(string(body))
-	d.eventValidation = parser.ExtractEventValidation(string(body))
+	// The response lists the location's dates too, so refresh them for free
+	locationValue := config.GetLocationValue(location)
+	listsDates := d.recordAvailableDates(locationValue, string(body))
 
 	// Parse HTML to extract food items
 	htmlContent := string(body)
@@ -266,7 +651,25 @@ func (d *DiningHallClient) GetMenu(location, date, mealType string) ([]string, e
 			fmt.Printf("DEBUG: Full HTML response:\n%s\n", htmlContent)
 		}
 	}
-	foods := parser.ParseFoodItems(htmlContent, d.Debug)
+	extraction := d.rules.Extract(htmlContent, d.Debug)
+	var foods []string
+	for _, item := range extraction.Items {
+		foods = append(foods, item.Name)
+	}
+	// Items expire with the menu cache, so the map doesn't grow with every menu ever fetched
+	d.menuItems.Set(menuKey(location, date, mealType), extraction.Items)
+	d.menuItems.CleanExpired()
+	if d.monitor != nil {
+		d.monitor.Record(health.Page{
+			Location:   location,
+			Date:       date,
+			MealType:   mealType,
+			HTML:       htmlContent,
+			ViewState:  parser.ExtractViewState(htmlContent) != "",
+			Expected:   !listsDates || slices.Contains(d.availableDates[locationValue].dates, date),
+			Extraction: extraction,
+		})
+	}
 	if d.Debug {
 		fmt.Printf("DEBUG: Found %d food items\n", len(foods))
 		if len(foods) == 0 {
@@ -296,12 +699,105 @@ func (d *DiningHallClient) GetMenu(location, date, mealType string) ([]string, e
 		}
 	}
 
-	// Store in cache (even if empty, to avoid repeated failed requests)
-	d.cache.Set(location, date, mealType, foods)
-
 	return foods, nil
 }
 
+// postMenuForm posts form fields to Menu.aspx along with the session's hidden
+// fields, updates the session state from the response, and returns the page;
+// callers must hold d.mu and have an active session. The request is
+// abandoned when ctx is done.
+func (d *DiningHallClient) postMenuForm(ctx context.Context, formData url.Values) ([]byte, error) {
+	// POST to Menu.aspx, not the base URL
+	menuURL := strings.TrimSuffix(d.baseURL, "/") + "/Menu.aspx"
+
+	formData.Set("__EVENTARGUMENT", "")
+	formData.Set("__VIEWSTATE", d.viewState)
+	formData.Set("__VIEWSTATEGENERATOR", d.viewStateGenerator)
+	formData.Set("__EVENTVALIDATION", d.eventValidation)
+
+	if d.Debug {
+		fmt.Printf("DEBUG: Posting to %s\n", menuURL)
+		fmt.Printf("DEBUG: Form data: %v\n", formData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", menuURL, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", d.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Origin", d.origin())
+	req.Header.Set("Referer", d.baseURL)
+
+	// Set cookies
+	for _, cookie := range d.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := d.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if d.Debug {
+		fmt.Printf("DEBUG: Response status: %d %s\n", resp.StatusCode, resp.Status)
+		fmt.Printf("DEBUG: Response headers:\n")
+		for key, values := range resp.Header {
+			fmt.Printf("DEBUG:   %s: %v\n", key, values)
+		}
+		fmt.Printf("DEBUG: Cookies received:\n")
+		cookies := d.jar.Cookies(req.URL)
+		for _, cookie := range cookies {
+			fmt.Printf("DEBUG:   %s=%s\n", cookie.Name, cookie.Value)
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if d.Debug {
+			fmt.Printf("DEBUG: Error response body: %s\n", string(body))
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update ViewState and EventValidation from response
+	d.viewState = parser.ExtractViewState(string(body))
+	d.eventValidation = parser.ExtractEventValidation(string(body))
+
+	return body, nil
+}
+
+// do sends a request to the site, waiting first if needed to respect the
+// minimum request interval
+func (d *DiningHallClient) do(req *http.Request) (*http.Response, error) {
+	if d.minInterval > 0 {
+		d.rateMu.Lock()
+		if wait := d.minInterval - time.Since(d.lastRequest); wait > 0 {
+			time.Sleep(wait)
+		}
+		d.lastRequest = time.Now()
+		d.rateMu.Unlock()
+	}
+	return d.client.Do(req)
+}
+
+// origin returns the scheme and host of the base URL, sent as the Origin header
+func (d *DiningHallClient) origin() string {
+	u, err := url.Parse(d.baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
 // GetBreakfastMenu is a convenience method for getting breakfast menus
 // Deprecated: Use GetMenu with "Breakfast" as mealType instead
 func (d *DiningHallClient) GetBreakfastMenu(location, date string) ([]string, error) {
@@ -1,9 +1,16 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/health"
+	"github.com/bklieger/diningbot/parser"
 )
 
 func TestNewDiningHallClient(t *testing.T) {
@@ -60,7 +67,7 @@ func TestInitializeSession(t *testing.T) {
 
 	client.SetBaseURL(server.URL + "/")
 
-	err = client.initializeSession()
+	err = client.initializeSession(context.Background())
 	if err != nil {
 		t.Fatalf("initializeSession() error = %v", err)
 	}
@@ -89,7 +96,7 @@ func TestInitializeSessionError(t *testing.T) {
 
 	client.SetBaseURL(server.URL + "/")
 
-	err = client.initializeSession()
+	err = client.initializeSession(context.Background())
 	if err == nil {
 		t.Error("initializeSession() should return error on 500 status")
 	}
@@ -103,7 +110,7 @@ func TestInitializeSessionNetworkError(t *testing.T) {
 
 	client.SetBaseURL("http://localhost:0/invalid")
 
-	err = client.initializeSession()
+	err = client.initializeSession(context.Background())
 	if err == nil {
 		t.Error("initializeSession() should return error on network failure")
 	}
@@ -328,3 +335,613 @@ func TestGetBreakfastMenu(t *testing.T) {
 		t.Error("GetBreakfastMenu() should return at least one item")
 	}
 }
+
+func TestRefreshMenu(t *testing.T) {
+	menus := []string{"Oatmeal", "Oatmeal", "Pancakes"}
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			html := `<html><body>
+				<table>
+					<tr><td class="MenuItem">` + menus[posts] + `</td></tr>
+				</table>
+				<input type="hidden" name="__VIEWSTATE" value="viewstate" />
+				<input type="hidden" name="__EVENTVALIDATION" value="validation" />
+			</body></html>`
+			posts++
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(html))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+
+	client.SetBaseURL(server.URL + "/")
+	client.viewState = "existing_viewstate"
+
+	// Uncached menus are never reported as changed
+	_, changed, err := client.RefreshMenu("Branner Dining", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("RefreshMenu() error = %v", err)
+	}
+	if changed {
+		t.Error("RefreshMenu() should not report change for uncached menu")
+	}
+
+	// Same items as cached
+	_, changed, err = client.RefreshMenu("Branner Dining", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("RefreshMenu() error = %v", err)
+	}
+	if changed {
+		t.Error("RefreshMenu() should not report change for identical menu")
+	}
+
+	// Different items than cached
+	foods, changed, err := client.RefreshMenu("Branner Dining", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("RefreshMenu() error = %v", err)
+	}
+	if !changed {
+		t.Error("RefreshMenu() should report change for different menu")
+	}
+	if len(foods) != 1 || foods[0] != "Pancakes" {
+		t.Errorf("RefreshMenu() = %v, want [Pancakes]", foods)
+	}
+
+	// The refreshed menu is served from cache afterwards
+	cached, err := client.GetMenu("Branner Dining", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	if len(cached) != 1 || cached[0] != "Pancakes" || posts != 3 {
+		t.Errorf("GetMenu() = %v after %d posts, want cached [Pancakes]", cached, posts)
+	}
+}
+
+func TestRefreshMenuInvalidLocation(t *testing.T) {
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+
+	if _, _, err := client.RefreshMenu("Invalid Location", "11/4/2024", "Breakfast"); err == nil {
+		t.Error("RefreshMenu() should return error for invalid location")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	pageLoads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Menu.aspx" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		pageLoads++
+		html := `<html><body><form>
+			<input type="hidden" name="__VIEWSTATE" value="viewstate" />
+			<select name="ctl00$MainContent$lstLocations">
+				<option value="Arrillaga">Arrillaga Family Dining Commons</option>
+				<option value="Munger">Munger Dining</option>
+			</select>
+			<select name="ctl00$MainContent$lstMealType">
+				<option value="Breakfast">Breakfast</option>
+				<option value="Late Night">Late Night</option>
+			</select>
+		</form></body></html>`
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL(server.URL + "/")
+
+	options, err := client.Options(context.Background())
+	if err != nil {
+		t.Fatalf("Options() error = %v", err)
+	}
+	if len(options.Locations) != 2 || options.Locations[1] != (parser.Option{Value: "Munger", Text: "Munger Dining"}) {
+		t.Errorf("Options().Locations = %v", options.Locations)
+	}
+	if len(options.MealTypes) != 2 || options.MealTypes[1].Value != "Late Night" {
+		t.Errorf("Options().MealTypes = %v", options.MealTypes)
+	}
+
+	// An established session is reused rather than reloading the page
+	loads := pageLoads
+	if _, err := client.Options(context.Background()); err != nil {
+		t.Fatalf("Options() error = %v", err)
+	}
+	if pageLoads != loads {
+		t.Errorf("Options() reloaded the page with an active session")
+	}
+}
+
+func TestOptionsNetworkError(t *testing.T) {
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL("http://invalid-domain-that-does-not-exist-12345.com/")
+
+	if _, err := client.Options(context.Background()); err == nil {
+		t.Error("Options() should return error when the page can't be loaded")
+	}
+}
+
+func TestOptionsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL(server.URL + "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Options(ctx); err == nil {
+		t.Error("Options() should return error when the page doesn't load in time")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Options() took %v, want it to stop when ctx is done", elapsed)
+	}
+}
+
+func TestAvailableDates(t *testing.T) {
+	datePosts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<input type="hidden" name="__VIEWSTATE" value="viewstate" />`))
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm error: %v", err)
+		}
+		if r.Form.Get("__VIEWSTATE") == "" {
+			t.Error("Expected the session ViewState to be posted")
+		}
+		if r.Form.Get("__EVENTTARGET") == "ctl00$MainContent$lstLocations" {
+			datePosts++
+			if got := r.Form.Get("ctl00$MainContent$lstLocations"); got != "FlorenceMoore" {
+				t.Errorf("Expected location FlorenceMoore, got %v", got)
+			}
+		}
+		html := `<html><body>
+			<input type="hidden" name="__VIEWSTATE" value="updated_viewstate" />
+			<select name="ctl00$MainContent$lstDay">
+				<option value="11/04/2024">Monday, November 4</option>
+				<option value="11/5/2024">Tuesday, November 5</option>
+			</select>
+			<table><tr><td class="MenuItem">Pancakes</td></tr></table>
+		</body></html>`
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL(server.URL + "/")
+
+	got, err := client.AvailableDates("FloMo")
+	if err != nil {
+		t.Fatalf("AvailableDates() error = %v", err)
+	}
+	want := []string{"11/4/2024", "11/5/2024"}
+	if !slices.Equal(got, want) {
+		t.Errorf("AvailableDates() = %v, want %v", got, want)
+	}
+	if client.viewState != "updated_viewstate" {
+		t.Errorf("viewState = %v, want updated_viewstate", client.viewState)
+	}
+
+	// Cached dates are reused without posting again
+	if _, err := client.AvailableDates("Florence Moore Dining"); err != nil {
+		t.Fatalf("AvailableDates() error = %v", err)
+	}
+	if datePosts != 1 {
+		t.Errorf("AvailableDates() posted %d times, want 1", datePosts)
+	}
+}
+
+func TestAvailableDatesFromMenuFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<input type="hidden" name="__VIEWSTATE" value="viewstate" />`))
+			return
+		}
+		r.ParseForm()
+		if r.Form.Get("__EVENTTARGET") == "ctl00$MainContent$lstLocations" {
+			t.Error("AvailableDates() should reuse the dates from the menu fetch")
+		}
+		html := `<select name="ctl00$MainContent$lstDay"><option value="11/4/2024">Monday</option></select>`
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL(server.URL + "/")
+
+	if _, err := client.GetMenu("Branner Dining", "11/4/2024", "Lunch"); err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	got, err := client.AvailableDates("Branner Dining")
+	if err != nil {
+		t.Fatalf("AvailableDates() error = %v", err)
+	}
+	if !slices.Equal(got, []string{"11/4/2024"}) {
+		t.Errorf("AvailableDates() = %v, want [11/4/2024]", got)
+	}
+}
+
+func TestAvailableDatesNoDropdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<input type="hidden" name="__VIEWSTATE" value="viewstate" />`))
+	}))
+	defer server.Close()
+
+	client, err := NewDiningHallClient()
+	if err != nil {
+		t.Fatalf("NewDiningHallClient() error = %v", err)
+	}
+	client.SetBaseURL(server.URL + "/")
+
+	if _, err := client.AvailableDates("Branner Dining"); err == nil {
+		t.Error("AvailableDates() should return error when the page lists no dates")
+	}
+	if _, err := client.AvailableDates("Nowhere"); err == nil {
+		t.Error("AvailableDates() should return error for invalid location")
+	}
+}
+
+func TestGetMenuWithParseRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `<html><body>
+			<div class="dish"><span class="dish-name">Shakshuka</span><span class="dish-tags">vegetarian</span></div>
+			<div class="dish"><span class="dish-name">Bagels</span></div>
+			<table><tr><td class="MenuItem">Old Markup Item</td></tr></table>
+			<input type="hidden" name="__VIEWSTATE" value="vs" />
+			<input type="hidden" name="__EVENTVALIDATION" value="ev" />
+		</body></html>`
+		w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL + "/"
+	cfg.ParseRules = []parser.Rule{{Name: "dish", Item: "div.dish", Fields: parser.Fields{Name: ".dish-name"}}}
+	client, err := NewDiningHallClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewDiningHallClientWithConfig() error = %v", err)
+	}
+
+	foods, err := client.GetMenu("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	if !slices.Equal(foods, []string{"Shakshuka", "Bagels"}) {
+		t.Errorf("GetMenu() = %v, want the items matched by the configured rule only", foods)
+	}
+}
+
+func TestNewDiningHallClientInvalidParseRules(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ParseRules = []parser.Rule{{Name: "broken"}}
+	if _, err := NewDiningHallClientWithConfig(cfg); err == nil {
+		t.Error("NewDiningHallClientWithConfig() should reject invalid parse rules")
+	}
+}
+
+func TestGetMenuRecordsParserHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `<html><body>
+			<select name="ctl00$MainContent$lstDay"><option value="11/4/2024">Monday</option></select>
+			<div id="MainContent_divRedesigned"><p class="menu-entry-v2">Shakshuka</p></div>
+			<input type="hidden" name="__VIEWSTATE" value="vs" />
+			<input type="hidden" name="__EVENTVALIDATION" value="ev" />
+		</body></html>`
+		w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	monitor := health.NewParserMonitor(1, "")
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL + "/"
+	cfg.Monitor = monitor
+	client, err := NewDiningHallClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewDiningHallClientWithConfig() error = %v", err)
+	}
+
+	// A date the page doesn't list has no menu to expect
+	if _, err := client.GetMenu("Branner Dining", "11/5/2024", "Lunch"); err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	if status := monitor.Status(); status.PagesChecked != 1 || status.Status != health.StatusOK {
+		t.Errorf("Status() = %+v, want ok after an empty menu for an unlisted date", status)
+	}
+
+	if _, err := client.GetMenu("Branner Dining", "11/4/2024", "Lunch"); err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	status := monitor.Status()
+	if status.Status != health.StatusDrift || !slices.Contains(status.Structure.IDs, "MainContent_divRedesigned") {
+		t.Errorf("Status() = %+v, want drift with the page structure", status)
+	}
+}
+
+// nutritionSite serves a menu whose items link to nutrition labels, counting label requests
+func nutritionSite(t *testing.T, labelRequests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Label.aspx":
+			labelRequests.Add(1)
+			if r.URL.Query().Get("id") == "missing" {
+				w.Write([]byte(`<html><body><p>Label not available</p></body></html>`))
+				return
+			}
+			w.Write([]byte(`<html><body><table>
+				<tr><td>Serving Size</td><td>1 cup</td></tr>
+				<tr><td>Calories</td><td>150</td></tr>
+				<tr><td>Protein</td><td>5g</td></tr>
+			</table></body></html>`))
+		default:
+			w.Write([]byte(`<html><body>
+				<div id="MainContent_divMenu">
+					<li class="clsMenuItem"><a href="Label.aspx?id=1"><span class="clsLabel_Name">Oatmeal</span></a>
+						<span class="clsLabel_Allergens">Allergens: oats</span></li>
+					<li class="clsMenuItem"><a href="Label.aspx?id=missing"><span class="clsLabel_Name">Toast</span></a></li>
+					<li class="clsMenuItem"><a href="javascript:void(0)"><span class="clsLabel_Name">Fruit</span></a></li>
+				</div>
+				<input type="hidden" name="__VIEWSTATE" value="vs" />
+				<input type="hidden" name="__EVENTVALIDATION" value="ev" />
+			</body></html>`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMenuItemsWithNutrition(t *testing.T) {
+	var labelRequests atomic.Int32
+	server := nutritionSite(t, &labelRequests)
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL + "/"
+	cfg.FetchNutrition = true
+	client, err := NewDiningHallClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewDiningHallClientWithConfig() error = %v", err)
+	}
+
+	items, err := client.MenuItems("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("MenuItems() = %+v, want 3 items", items)
+	}
+	oatmeal := items[0]
+	if oatmeal.Allergens != "oats" || oatmeal.Nutrition == nil || *oatmeal.Nutrition.Calories != 150 || oatmeal.Nutrition.ServingSize != "1 cup" {
+		t.Errorf("Oatmeal = %+v, want allergens and its nutrition label", oatmeal)
+	}
+	if items[1].Nutrition != nil || items[2].Nutrition != nil {
+		t.Error("items without a label or a followable link should have no nutrition")
+	}
+	if labelRequests.Load() != 2 {
+		t.Errorf("label requests = %d, want 2", labelRequests.Load())
+	}
+
+	// Labels are cached per item, so another menu serving them fetches nothing
+	if _, err := client.MenuItems("Branner Dining", "11/4/2024", "Breakfast"); err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+	if labelRequests.Load() != 2 {
+		t.Errorf("label requests = %d after a second menu, want labels reused", labelRequests.Load())
+	}
+}
+
+func TestMenuItemsWithoutNutrition(t *testing.T) {
+	var labelRequests atomic.Int32
+	server := nutritionSite(t, &labelRequests)
+
+	client, _ := NewDiningHallClient()
+	client.SetBaseURL(server.URL + "/")
+
+	items, err := client.MenuItems("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+	if len(items) != 3 || items[0].Link != "Label.aspx?id=1" || items[0].Nutrition != nil {
+		t.Errorf("MenuItems() = %+v, want links but no nutrition", items)
+	}
+	if labelRequests.Load() != 0 {
+		t.Errorf("label requests = %d, want none without FetchNutrition", labelRequests.Load())
+	}
+}
+
+func TestItemDetails(t *testing.T) {
+	var labelRequests atomic.Int32
+	server := nutritionSite(t, &labelRequests)
+
+	client, _ := NewDiningHallClient()
+	client.SetBaseURL(server.URL + "/")
+
+	item, err := client.ItemDetails("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast", "  oatmeal ")
+	if err != nil {
+		t.Fatalf("ItemDetails() error = %v", err)
+	}
+	if item.Name != "Oatmeal" || item.Nutrition == nil || *item.Nutrition.Protein != 5 {
+		t.Errorf("ItemDetails() = %+v, want Oatmeal with its label", item)
+	}
+	if labelRequests.Load() != 1 {
+		t.Errorf("label requests = %d, want 1", labelRequests.Load())
+	}
+
+	if _, err := client.ItemDetails("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast", "Pancakes"); err == nil {
+		t.Error("ItemDetails() should fail for an item not on the menu")
+	}
+}
+
+func TestMenuItemsNutritionDoesNotBlockMenus(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/Label.aspx" {
+			started <- struct{}{}
+			<-release
+			w.Write([]byte(`<html><body><table><tr><td>Calories</td><td>150</td></tr></table></body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><body>
+			<div id="MainContent_divMenu">
+				<li class="clsMenuItem"><a href="Label.aspx?id=1"><span class="clsLabel_Name">Oatmeal</span></a></li>
+			</div>
+			<input type="hidden" name="__VIEWSTATE" value="vs" />
+			<input type="hidden" name="__EVENTVALIDATION" value="ev" />
+		</body></html>`))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL + "/"
+	cfg.FetchNutrition = true
+	client, err := NewDiningHallClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewDiningHallClientWithConfig() error = %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := client.MenuItems("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+		done <- err
+	}()
+	<-started
+
+	// Another menu is fetched while the label request is still in flight
+	if _, err := client.GetMenu("Branner Dining", "11/4/2024", "Lunch"); err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+}
+
+func TestMenuItemsExpire(t *testing.T) {
+	var labelRequests atomic.Int32
+	server := nutritionSite(t, &labelRequests)
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL + "/"
+	cfg.CacheTTL = time.Millisecond
+	client, err := NewDiningHallClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewDiningHallClientWithConfig() error = %v", err)
+	}
+
+	for _, date := range []string{"11/4/2024", "11/5/2024", "11/6/2024"} {
+		time.Sleep(2 * time.Millisecond)
+		if _, err := client.GetMenu("Branner Dining", date, "Lunch"); err != nil {
+			t.Fatalf("GetMenu() error = %v", err)
+		}
+	}
+	if n := client.menuItems.Len(); n != 1 {
+		t.Errorf("menuItems holds %d menus, want expired ones pruned", n)
+	}
+}
+
+func TestFillNutrition(t *testing.T) {
+	var labelRequests atomic.Int32
+	server := nutritionSite(t, &labelRequests)
+
+	client, _ := NewDiningHallClient()
+	client.SetBaseURL(server.URL + "/")
+	items, err := client.MenuItems("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+	if err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+
+	// A cancelled context fetches nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if fetched, skipped := client.FillNutrition(ctx, items, 10); fetched != 0 || skipped != 2 {
+		t.Errorf("FillNutrition() with a cancelled context = %d, %d, want 0 fetched and 2 skipped", fetched, skipped)
+	}
+
+	// Items without a followable link are neither fetched nor skipped
+	if fetched, skipped := client.FillNutrition(context.Background(), items, 1); fetched != 1 || skipped != 1 {
+		t.Errorf("FillNutrition() with a limit of 1 = %d, %d, want 1 fetched and 1 skipped", fetched, skipped)
+	}
+	if items[0].Nutrition == nil || items[1].Nutrition != nil {
+		t.Errorf("FillNutrition() = %+v, want only the first label read", items)
+	}
+
+	// Cached labels don't count toward the limit
+	items, _ = client.MenuItems("Arrillaga Family Dining Commons", "11/4/2024", "Breakfast")
+	if fetched, skipped := client.FillNutrition(context.Background(), items, 1); fetched != 1 || skipped != 0 || items[0].Nutrition == nil {
+		t.Errorf("FillNutrition() = %d, %d, want the cached label used and 1 fetched", fetched, skipped)
+	}
+	if n := labelRequests.Load(); n != 2 {
+		t.Errorf("label requests = %d, want 2", n)
+	}
+}
+
+func TestFillNutritionCancelsFetches(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, _ := NewDiningHallClient()
+	client.SetBaseURL(server.URL + "/")
+	items := []parser.Item{{Name: "Oatmeal", Link: "Label.aspx?id=1"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+	done := make(chan struct{})
+	go func() {
+		client.FillNutrition(ctx, items, 1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("FillNutrition() kept waiting for a label after ctx was cancelled")
+	}
+	if items[0].Nutrition != nil {
+		t.Errorf("FillNutrition() = %+v, want no label", items)
+	}
+}
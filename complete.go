@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bklieger/diningbot/completion"
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// completionDays is how many upcoming dates are suggested for date arguments
+const completionDays = 7
+
+// Complete handles completion/complete for prompt arguments and resource template
+// variables. Tools, prompts and templates share argument names, so completion is
+// keyed on the argument name rather than the reference.
+func Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	arg := req.Params.Argument
+
+	var values []string
+	switch arg.Name {
+	case "location":
+		values = completion.Match(config.ValidLocations, arg.Value)
+	case "mealType":
+		values = completion.Match(config.ValidMealTypes, arg.Value)
+	case "date", "startDate":
+		values = completion.Dates(dates.Now(), completionDays, arg.Value)
+		// Menu resource URIs also accept "today"
+		if req.Params.Ref.Type == "ref/resource" && strings.HasPrefix("today", arg.Value) {
+			values = append([]string{"today"}, values...)
+		}
+	default:
+		values = []string{}
+	}
+
+	total := len(values)
+	values = completion.Limit(values)
+	return &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values:  values,
+			Total:   total,
+			HasMore: len(values) < total,
+		},
+	}, nil
+}
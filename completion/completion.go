@@ -0,0 +1,98 @@
+package completion
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bklieger/diningbot/utils"
+)
+
+// MaxValues is the maximum number of completion values returned, per the MCP spec
+const MaxValues = 100
+
+// Match returns the candidates matching value, best matches first.
+// Matching is case-insensitive and ranks, in order: whole-string prefix matches,
+// word prefix matches, substring matches, and fuzzy matches (characters in order
+// starting at a word, so "flmr" finds "Florence Moore Dining").
+// An empty value matches every candidate. Callers cap the result with Limit.
+func Match(candidates []string, value string) []string {
+	needle := strings.ToLower(strings.TrimSpace(value))
+	if needle == "" {
+		return append([]string{}, candidates...)
+	}
+
+	var prefix, wordPrefix, substring, fuzzy []string
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lower, needle):
+			prefix = append(prefix, candidate)
+		case hasWordPrefix(lower, needle):
+			wordPrefix = append(wordPrefix, candidate)
+		case strings.Contains(lower, needle):
+			substring = append(substring, candidate)
+		case isSubsequence(lower, needle):
+			fuzzy = append(fuzzy, candidate)
+		}
+	}
+
+	result := append([]string{}, prefix...)
+	result = append(result, wordPrefix...)
+	result = append(result, substring...)
+	result = append(result, fuzzy...)
+	return result
+}
+
+// Dates suggests the next days dates starting from now, in utils.FormatDate format,
+// keeping those that start with prefix
+func Dates(now time.Time, days int, prefix string) []string {
+	prefix = strings.TrimSpace(prefix)
+	result := []string{}
+	for i := 0; i < days; i++ {
+		date := utils.FormatDate(now.AddDate(0, 0, i))
+		if strings.HasPrefix(date, prefix) {
+			result = append(result, date)
+		}
+	}
+	return result
+}
+
+// hasWordPrefix reports whether any word of s starts with prefix
+func hasWordPrefix(s, prefix string) bool {
+	for _, word := range strings.Fields(s) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order,
+// starting at the beginning of a word so short inputs don't match everything
+func isSubsequence(s, sub string) bool {
+	needle := []rune(sub)
+	words := strings.Fields(s)
+	for i, word := range words {
+		if []rune(word)[0] != needle[0] {
+			continue
+		}
+		rest := needle
+		for _, r := range strings.Join(words[i:], " ") {
+			if len(rest) > 0 && r == rest[0] {
+				rest = rest[1:]
+			}
+		}
+		if len(rest) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit returns the first MaxValues values
+func Limit(values []string) []string {
+	if len(values) > MaxValues {
+		return values[:MaxValues]
+	}
+	return values
+}
@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testLocations = []string{
+	"Arrillaga Family Dining Commons",
+	"Branner Dining",
+	"EVGR Dining",
+	"Florence Moore Dining",
+	"Gerhard Casper Dining",
+	"Lakeside Dining",
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"prefix", "bran", []string{"Branner Dining"}},
+		{"case insensitive", "ARRI", []string{"Arrillaga Family Dining Commons"}},
+		{"word prefix", "casp", []string{"Gerhard Casper Dining"}},
+		{"substring", "side", []string{"Lakeside Dining"}},
+		{"fuzzy", "flmr", []string{"Florence Moore Dining"}},
+		{"ranked", "l", []string{"Lakeside Dining", "Arrillaga Family Dining Commons", "Florence Moore Dining"}},
+		{"no match", "xyz", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Match(testLocations, tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchEmptyValue(t *testing.T) {
+	got := Match(testLocations, "")
+	if !reflect.DeepEqual(got, testLocations) {
+		t.Errorf("Match(\"\") = %v, want all candidates", got)
+	}
+
+	// The result must not alias the candidates slice
+	got[0] = "changed"
+	if testLocations[0] == "changed" {
+		t.Error("Match() returned the candidates slice itself")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	candidates := make([]string, MaxValues+10)
+	for i := range candidates {
+		candidates[i] = "Item"
+	}
+	got := Match(candidates, "it")
+	if len(got) != MaxValues+10 {
+		t.Errorf("Match() returned %d values, want all %d", len(got), MaxValues+10)
+	}
+	if got := Limit(got); len(got) != MaxValues {
+		t.Errorf("Limit() returned %d values, want %d", len(got), MaxValues)
+	}
+}
+
+func TestDates(t *testing.T) {
+	now := time.Date(2025, 1, 29, 15, 0, 0, 0, time.UTC)
+
+	got := Dates(now, 7, "")
+	want := []string{"1/29/2025", "1/30/2025", "1/31/2025", "2/1/2025", "2/2/2025", "2/3/2025", "2/4/2025"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dates() = %v, want %v", got, want)
+	}
+
+	got = Dates(now, 7, "2/")
+	want = []string{"2/1/2025", "2/2/2025", "2/3/2025", "2/4/2025"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dates(\"2/\") = %v, want %v", got, want)
+	}
+
+	if got := Dates(now, 7, "12/"); len(got) != 0 {
+		t.Errorf("Dates(\"12/\") = %v, want none", got)
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LocationAliases maps normalized nicknames to location display names
+var LocationAliases = map[string]string{
+	"arrillaga":      "Arrillaga Family Dining Commons",
+	"afdc":           "Arrillaga Family Dining Commons",
+	"arrillaga fdc":  "Arrillaga Family Dining Commons",
+	"branner":        "Branner Dining",
+	"evgr":           "EVGR Dining",
+	"escondido":      "EVGR Dining",
+	"flomo":          "Florence Moore Dining",
+	"florence moore": "Florence Moore Dining",
+	"fmore":          "Florence Moore Dining",
+	"casper":         "Gerhard Casper Dining",
+	"gerhard casper": "Gerhard Casper Dining",
+	"gcc":            "Gerhard Casper Dining",
+	"lakeside":       "Lakeside Dining",
+	"lag":            "Lakeside Dining",
+	"ricker":         "Ricker Dining",
+	"stern":          "Stern Dining",
+	"wilbur":         "Wilbur Dining",
+}
+
+// MealTypeAliases maps normalized nicknames to meal types
+var MealTypeAliases = map[string]string{
+	"brekkie":        "Breakfast",
+	"bfast":          "Breakfast",
+	"morning":        "Breakfast",
+	"midday":         "Lunch",
+	"supper":         "Dinner",
+	"evening":        "Dinner",
+	"weekend brunch": "Brunch",
+}
+
+// maxSuggestions caps the "did you mean" suggestions in errors
+const maxSuggestions = 3
+
+// NormalizeLocation resolves a location given as a display name, API value or
+// nickname (case-insensitive, e.g. "FloMo", "Casper", "lakeside") to its display name.
+// Unknown locations return an error with "did you mean" suggestions.
+func NormalizeLocation(input string) (string, error) {
+	if IsValidLocation(input) {
+		return input, nil
+	}
+	key := normalizeKey(input)
+	if key != "" {
+		for _, name := range ValidLocations {
+			if normalizeKey(name) == key || strings.ToLower(GetLocationValue(name)) == key {
+				return name, nil
+			}
+		}
+		if name, ok := LocationAliases[key]; ok {
+			return name, nil
+		}
+		if name, ok := LocationAliases[stripLocationSuffix(key)]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("invalid location: %s%s", input, didYouMean(SuggestLocations(input)))
+}
+
+// NormalizeMealType resolves a meal type given in any case or as a nickname
+// (e.g. "supper") to its canonical name.
+// Unknown meal types return an error with "did you mean" suggestions.
+func NormalizeMealType(input string) (string, error) {
+	if IsValidMealType(input) {
+		return input, nil
+	}
+	key := normalizeKey(input)
+	if key != "" {
+		for _, mt := range ValidMealTypes {
+			if strings.ToLower(mt) == key {
+				return mt, nil
+			}
+		}
+		if mt, ok := MealTypeAliases[key]; ok {
+			return mt, nil
+		}
+	}
+	return "", fmt.Errorf("invalid meal type: %s%s", input, didYouMean(SuggestMealTypes(input)))
+}
+
+// SuggestLocations returns the locations whose names or nicknames are closest to input
+func SuggestLocations(input string) []string {
+	candidates := make(map[string]string)
+	for _, name := range ValidLocations {
+		candidates[normalizeKey(name)] = name
+		candidates[stripLocationSuffix(normalizeKey(name))] = name
+		candidates[strings.ToLower(GetLocationValue(name))] = name
+	}
+	for alias, name := range LocationAliases {
+		candidates[alias] = name
+	}
+	return suggest(stripLocationSuffix(normalizeKey(input)), candidates)
+}
+
+// SuggestMealTypes returns the meal types whose names or nicknames are closest to input
+func SuggestMealTypes(input string) []string {
+	candidates := make(map[string]string)
+	for _, mt := range ValidMealTypes {
+		candidates[strings.ToLower(mt)] = mt
+	}
+	for alias, mt := range MealTypeAliases {
+		candidates[alias] = mt
+	}
+	return suggest(normalizeKey(input), candidates)
+}
+
+// suggest ranks canonical values by the edit distance of their closest candidate key
+func suggest(key string, candidates map[string]string) []string {
+	if key == "" {
+		return nil
+	}
+
+	best := make(map[string]int)
+	for candidate, canonical := range candidates {
+		d := editDistance(key, candidate)
+		// Prefix matches such as "flor" for "florence moore" count as close
+		if strings.HasPrefix(candidate, key) && len(key) >= 3 {
+			d = 1
+		}
+		if d > maxDistance(key) {
+			continue
+		}
+		if prev, ok := best[canonical]; !ok || d < prev {
+			best[canonical] = d
+		}
+	}
+
+	result := make([]string, 0, len(best))
+	for canonical := range best {
+		result = append(result, canonical)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if best[result[i]] != best[result[j]] {
+			return best[result[i]] < best[result[j]]
+		}
+		return result[i] < result[j]
+	})
+	if len(result) > maxSuggestions {
+		result = result[:maxSuggestions]
+	}
+	return result
+}
+
+// maxDistance is the largest edit distance still considered a plausible typo
+func maxDistance(key string) int {
+	if n := len(key) / 3; n > 2 {
+		return n
+	}
+	return 2
+}
+
+// didYouMean formats suggestions for an error message
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(quoted, " or "))
+}
+
+// normalizeKey lowercases input, drops punctuation and collapses whitespace
+func normalizeKey(input string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(input) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// stripLocationSuffix drops generic words like "dining hall" from a normalized key
+func stripLocationSuffix(key string) string {
+	for _, suffix := range []string{" dining hall", " dining commons", " dining", " hall", " commons"} {
+		if strings.HasSuffix(key, suffix) {
+			return strings.TrimSuffix(key, suffix)
+		}
+	}
+	return key
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
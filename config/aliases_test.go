@@ -0,0 +1,129 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLocation(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Arrillaga Family Dining Commons", "Arrillaga Family Dining Commons"},
+		{"arrillaga family dining commons", "Arrillaga Family Dining Commons"},
+		{"Arrillaga", "Arrillaga Family Dining Commons"},
+		{"AFDC", "Arrillaga Family Dining Commons"},
+		{"FloMo", "Florence Moore Dining"},
+		{"FlorenceMoore", "Florence Moore Dining"},
+		{"Florence Moore", "Florence Moore Dining"},
+		{"Casper", "Gerhard Casper Dining"},
+		{"EVGR", "EVGR Dining"},
+		{"lakeside", "Lakeside Dining"},
+		{"Lakeside Dining Hall", "Lakeside Dining"},
+		{"  wilbur  ", "Wilbur Dining"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeLocation(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeLocation(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeLocation(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeLocationSuggestions(t *testing.T) {
+	tests := []struct {
+		input   string
+		suggest string
+	}{
+		{"Arrilaga", "Arrillaga Family Dining Commons"},
+		{"Lakesid", "Lakeside Dining"},
+		{"Branor Dining", "Branner Dining"},
+		{"flor", "Florence Moore Dining"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := NormalizeLocation(tt.input)
+			if err == nil {
+				t.Fatalf("NormalizeLocation(%q) should return error", tt.input)
+			}
+			if !strings.Contains(err.Error(), "did you mean") || !strings.Contains(err.Error(), tt.suggest) {
+				t.Errorf("NormalizeLocation(%q) error = %q, want suggestion %q", tt.input, err, tt.suggest)
+			}
+		})
+	}
+
+	_, err := NormalizeLocation("Invalid Location")
+	if err == nil {
+		t.Fatal("NormalizeLocation(\"Invalid Location\") should return error")
+	}
+	if strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("unrelated input should not get suggestions, got %q", err)
+	}
+}
+
+func TestNormalizeMealType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Lunch", "Lunch"},
+		{"breakfast", "Breakfast"},
+		{"DINNER", "Dinner"},
+		{"supper", "Dinner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeMealType(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeMealType(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeMealType(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	_, err := NormalizeMealType("Lnch")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "Lunch"?`) {
+		t.Errorf("NormalizeMealType(\"Lnch\") error = %v, want suggestion for Lunch", err)
+	}
+	if _, err := NormalizeMealType(""); err == nil {
+		t.Error("NormalizeMealType(\"\") should return error")
+	}
+}
+
+func TestSuggestLocationsOrdering(t *testing.T) {
+	got := SuggestLocations("Stirn")
+	if len(got) == 0 || got[0] != "Stern Dining" {
+		t.Errorf("SuggestLocations(\"Stirn\") = %v, want Stern Dining first", got)
+	}
+	if len(got) > maxSuggestions {
+		t.Errorf("SuggestLocations() returned %d values, want at most %d", len(got), maxSuggestions)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flomo", "flomo", 0},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -58,3 +58,59 @@ func IsValidMealType(mealType string) bool {
 	}
 	return false
 }
+
+// Location is a dining hall as listed in the menu site's location dropdown
+type Location struct {
+	Name  string `json:"name"`  // display name
+	Value string `json:"value"` // API value posted to the site
+}
+
+// MergeDiscovered merges locations and meal types discovered on the menu site
+// into the static lists, so newly opened halls work without a code change.
+// A known hall keeps its static display name; if the site labels it differently,
+// the site's label becomes a nickname. A known name with a new API value is
+// updated. Static entries the site no longer lists are kept.
+// It returns the location and meal type names that were added. It is not safe to
+// call while other goroutines read the config, so call it before serving.
+func MergeDiscovered(locations []Location, mealTypes []string) []string {
+	var added []string
+
+	namesByValue := make(map[string]string, len(LocationMap))
+	for name, value := range LocationMap {
+		namesByValue[value] = name
+	}
+
+	for _, loc := range locations {
+		if loc.Name == "" || loc.Value == "" {
+			continue
+		}
+		if _, exists := LocationMap[loc.Name]; exists {
+			LocationMap[loc.Name] = loc.Value
+			namesByValue[loc.Value] = loc.Name
+			continue
+		}
+		if name, exists := namesByValue[loc.Value]; exists {
+			if key := normalizeKey(loc.Name); key != "" {
+				LocationAliases[key] = name
+			}
+			continue
+		}
+		LocationMap[loc.Name] = loc.Value
+		ValidLocations = append(ValidLocations, loc.Name)
+		namesByValue[loc.Value] = loc.Name
+		added = append(added, loc.Name)
+	}
+
+	for _, mealType := range mealTypes {
+		if mealType == "" {
+			continue
+		}
+		if _, err := NormalizeMealType(mealType); err == nil {
+			continue
+		}
+		ValidMealTypes = append(ValidMealTypes, mealType)
+		added = append(added, mealType)
+	}
+
+	return added
+}
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"maps"
+	"slices"
+	"testing"
+)
 
 func TestIsValidLocation(t *testing.T) {
 	tests := []struct {
@@ -47,3 +51,67 @@ func TestIsValidMealType(t *testing.T) {
 		})
 	}
 }
+
+// restoreConfig snapshots the mutable config lists and restores them when the test ends
+func restoreConfig(t *testing.T) {
+	t.Helper()
+	locationMap := maps.Clone(LocationMap)
+	aliases := maps.Clone(LocationAliases)
+	locations := slices.Clone(ValidLocations)
+	mealTypes := slices.Clone(ValidMealTypes)
+	t.Cleanup(func() {
+		LocationMap = locationMap
+		LocationAliases = aliases
+		ValidLocations = locations
+		ValidMealTypes = mealTypes
+	})
+}
+
+func TestMergeDiscovered(t *testing.T) {
+	restoreConfig(t)
+
+	added := MergeDiscovered([]Location{
+		{Name: "Arrillaga Family Dining Commons", Value: "Arrillaga"},
+		{Name: "Branner Dining Hall", Value: "Branner"},
+		{Name: "Wilbur Dining", Value: "WilburHall"},
+		{Name: "Munger Dining", Value: "Munger"},
+		{Name: "", Value: "Empty"},
+	}, []string{"Breakfast", "dinner", "Late Night"})
+
+	want := []string{"Munger Dining", "Late Night"}
+	if !slices.Equal(added, want) {
+		t.Errorf("MergeDiscovered() added = %v, want %v", added, want)
+	}
+
+	// New halls are valid and resolvable by API value
+	if !IsValidLocation("Munger Dining") || GetLocationValue("Munger Dining") != "Munger" {
+		t.Error("discovered location should be valid with its API value")
+	}
+	if name, err := NormalizeLocation("munger"); err != nil || name != "Munger Dining" {
+		t.Errorf("NormalizeLocation(\"munger\") = %q, %v", name, err)
+	}
+
+	// A renamed hall keeps its static name and the site label becomes a nickname
+	if IsValidLocation("Branner Dining Hall") {
+		t.Error("site label for a known hall should not become a separate location")
+	}
+	if name, err := NormalizeLocation("Branner Dining Hall"); err != nil || name != "Branner Dining" {
+		t.Errorf("NormalizeLocation(\"Branner Dining Hall\") = %q, %v", name, err)
+	}
+
+	// A known name with a new API value is updated
+	if got := GetLocationValue("Wilbur Dining"); got != "WilburHall" {
+		t.Errorf("GetLocationValue(\"Wilbur Dining\") = %q, want WilburHall", got)
+	}
+
+	// Static entries the site didn't list are kept
+	if !IsValidLocation("Stern Dining") || !IsValidMealType("Brunch") {
+		t.Error("static entries should be kept")
+	}
+	if !IsValidMealType("Late Night") {
+		t.Error("discovered meal type should be valid")
+	}
+	if n := len(ValidMealTypes); n != 5 {
+		t.Errorf("ValidMealTypes has %d entries, want 5 (case variants must not duplicate)", n)
+	}
+}
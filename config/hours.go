@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ServiceHours is when a meal is served, as campus-local "HH:MM" clock times
+type ServiceHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// DefaultServiceHours are typical dining hall hours for each meal type
+var DefaultServiceHours = map[string]ServiceHours{
+	"Breakfast": {Start: "07:30", End: "10:00"},
+	"Brunch":    {Start: "10:00", End: "14:00"},
+	"Lunch":     {Start: "11:00", End: "14:00"},
+	"Dinner":    {Start: "17:00", End: "20:00"},
+}
+
+// clockLayout is the format of service hour times
+const clockLayout = "15:04"
+
+// Times returns the start and end of service on the given day, in day's
+// location. An end before the start is taken to be after midnight.
+func (h ServiceHours) Times(day time.Time) (time.Time, time.Time, error) {
+	start, err := time.Parse(clockLayout, h.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start time %q, want HH:MM", h.Start)
+	}
+	end, err := time.Parse(clockLayout, h.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end time %q, want HH:MM", h.End)
+	}
+	at := func(clock time.Time, days int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day()+days, clock.Hour(), clock.Minute(), 0, 0, day.Location())
+	}
+	if end.After(start) {
+		return at(start, 0), at(end, 0), nil
+	}
+	return at(start, 0), at(end, 1), nil
+}
+
+// Hours returns the service hours of a meal at a location. Configured hours
+// for "Location/Meal Type" win over those for the meal type, which win over
+// DefaultServiceHours. It reports false if no hours are known.
+func (s *Settings) Hours(location, mealType string) (ServiceHours, bool) {
+	if h, ok := s.ServiceHours[location+"/"+mealType]; ok {
+		return h, true
+	}
+	if h, ok := s.ServiceHours[mealType]; ok {
+		return h, true
+	}
+	h, ok := DefaultServiceHours[mealType]
+	return h, ok
+}
+
+// validateServiceHours checks configured service hours
+func (s *Settings) validateServiceHours() []error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(s.ServiceHours)) {
+		h := s.ServiceHours[key]
+		if strings.TrimSpace(key) == "" {
+			errs = append(errs, fmt.Errorf("serviceHours keys must be a meal type or \"Location/Meal Type\""))
+			continue
+		}
+		if _, _, err := h.Times(time.Time{}); err != nil {
+			errs = append(errs, fmt.Errorf("serviceHours[%q]: %w", key, err))
+		}
+	}
+	return errs
+}
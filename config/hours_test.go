@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServiceHoursTimes(t *testing.T) {
+	campus, _ := time.LoadLocation("America/Los_Angeles")
+	day := time.Date(2025, 3, 9, 15, 0, 0, 0, campus) // DST starts at 2am
+
+	start, end, err := ServiceHours{Start: "07:30", End: "10:00"}.Times(day)
+	if err != nil {
+		t.Fatalf("Times() error = %v", err)
+	}
+	if want := time.Date(2025, 3, 9, 7, 30, 0, 0, campus); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	if want := time.Date(2025, 3, 9, 10, 0, 0, 0, campus); !end.Equal(want) {
+		t.Errorf("end = %v, want %v", end, want)
+	}
+
+	// Late night service ends after midnight
+	start, end, _ = ServiceHours{Start: "21:00", End: "01:00"}.Times(day)
+	if end.Sub(start) != 4*time.Hour || end.Day() != 10 {
+		t.Errorf("late night = %v to %v, want 4 hours ending the next day", start, end)
+	}
+
+	if _, _, err := (ServiceHours{Start: "7am", End: "10:00"}).Times(day); err == nil {
+		t.Error("Times() should reject times not in HH:MM format")
+	}
+}
+
+func TestSettingsHours(t *testing.T) {
+	s := DefaultSettings()
+	s.ServiceHours = map[string]ServiceHours{
+		"Dinner":                {Start: "16:30", End: "19:30"},
+		"Branner Dining/Dinner": {Start: "17:00", End: "21:00"},
+	}
+
+	tests := []struct {
+		location, mealType string
+		want               ServiceHours
+		ok                 bool
+	}{
+		{"Branner Dining", "Dinner", ServiceHours{Start: "17:00", End: "21:00"}, true},
+		{"Wilbur Dining", "Dinner", ServiceHours{Start: "16:30", End: "19:30"}, true},
+		{"Wilbur Dining", "Lunch", DefaultServiceHours["Lunch"], true},
+		{"Wilbur Dining", "Late Night", ServiceHours{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := s.Hours(tt.location, tt.mealType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Hours(%q, %q) = %+v, %v, want %+v, %v", tt.location, tt.mealType, got, ok, tt.want, tt.ok)
+		}
+	}
+}
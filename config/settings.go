@@ -0,0 +1,399 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/parser"
+)
+
+// Defaults for settings not given in the config file or environment
+const (
+	DefaultUserAgent           = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36"
+	DefaultHTTPTimeout         = 30 * time.Second
+	DefaultCacheTTL            = 1 * time.Hour
+	DefaultAvailableDatesTTL   = 1 * time.Hour
+	DefaultBindAddr            = "127.0.0.1"
+	DefaultMenuRefreshInterval = 15 * time.Minute
+	DefaultWatchInterval       = 1 * time.Hour
+	DefaultRangeDays           = 7
+	DefaultMaxRangeDays        = 30
+	DefaultDriftThreshold      = 3
+	DefaultNutritionTTL        = 24 * time.Hour
+	DefaultDiscoverTimeout     = 5 * time.Second
+)
+
+// ConfigPathEnv names the environment variable holding the config file path
+const ConfigPathEnv = "DININGBOT_CONFIG"
+
+// Duration is a time.Duration that reads and writes JSON strings like "30s" or "1h"
+type Duration time.Duration
+
+// UnmarshalJSON parses a Go duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// SiteSettings configure how the menu site is fetched
+type SiteSettings struct {
+	BaseURL   string   `json:"baseUrl"`
+	UserAgent string   `json:"userAgent"`
+	Timeout   Duration `json:"timeout"`
+	// MinRequestInterval rate-limits requests to the site; zero disables the limit
+	MinRequestInterval Duration `json:"minRequestInterval"`
+	// Discover merges the site's location and meal type dropdowns into the lists at startup
+	Discover bool `json:"discover"`
+	// DiscoverTimeout bounds discovery so an unreachable site doesn't hold up startup
+	DiscoverTimeout Duration `json:"discoverTimeout"`
+	// FetchNutrition follows item links to read nutrition labels for whole menus.
+	// Single items are looked up on request either way.
+	FetchNutrition bool `json:"fetchNutrition"`
+}
+
+// CacheSettings configure how long fetched data is reused
+type CacheSettings struct {
+	TTL               Duration `json:"ttl"`
+	AvailableDatesTTL Duration `json:"availableDatesTtl"`
+	// NutritionTTL is how long an item's nutrition label is reused
+	NutritionTTL Duration `json:"nutritionTtl"`
+}
+
+// ServerSettings configure the MCP server
+type ServerSettings struct {
+	// Port enables the Streamable HTTP transport; empty means stdio
+	Port                string   `json:"port"`
+	BindAddr            string   `json:"bindAddr"`
+	CampusTimezone      string   `json:"campusTimezone"`
+	MenuRefreshInterval Duration `json:"menuRefreshInterval"`
+	DefaultRangeDays    int      `json:"defaultRangeDays"`
+	MaxRangeDays        int      `json:"maxRangeDays"`
+}
+
+// SMTPSettings configure email delivery of watchlist alerts
+type SMTPSettings struct {
+	Addr     string   `json:"addr"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	User     string   `json:"user"`
+	Password string   `json:"password"`
+}
+
+// WatchSettings configure the watchlist scanner and its notifiers
+type WatchSettings struct {
+	Interval   Duration     `json:"interval"`
+	WebhookURL string       `json:"webhookUrl"`
+	SMTP       SMTPSettings `json:"smtp"`
+}
+
+// ProviderSettings configure an additional menu provider, such as another
+// campus's menu site or a static JSON file of menus
+type ProviderSettings struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+}
+
+// ParsingSettings configure how menu items are found in menu pages
+type ParsingSettings struct {
+	// Rules replace the built-in extraction rules when set
+	Rules []parser.Rule `json:"rules,omitempty"`
+	// DriftThreshold is how many halls must return menu pages without items
+	// before parser health reports that the site's markup may have changed
+	DriftThreshold int `json:"driftThreshold"`
+	// DumpDir, if set, receives the HTML of pages that triggered a drift alert
+	DumpDir string `json:"dumpDir,omitempty"`
+}
+
+// Settings is the runtime configuration, built from defaults, an optional
+// JSON config file and environment variable overrides, in that order
+type Settings struct {
+	Site   SiteSettings   `json:"site"`
+	Cache  CacheSettings  `json:"cache"`
+	Server ServerSettings `json:"server"`
+	Watch  WatchSettings  `json:"watch"`
+	// Parsing holds the menu page extraction rules
+	Parsing ParsingSettings `json:"parsing"`
+	// Locations and MealTypes replace the built-in lists when set
+	Locations []Location `json:"locations,omitempty"`
+	MealTypes []string   `json:"mealTypes,omitempty"`
+	// Providers serve locations in addition to the Stanford menu site
+	Providers []ProviderSettings `json:"providers,omitempty"`
+	// ServiceHours override DefaultServiceHours, keyed by meal type or by
+	// "Location/Meal Type" for one hall (see Hours)
+	ServiceHours map[string]ServiceHours `json:"serviceHours,omitempty"`
+}
+
+// DefaultSettings returns the settings used when nothing is configured
+func DefaultSettings() *Settings {
+	return &Settings{
+		Site: SiteSettings{
+			BaseURL:         DefaultBaseURL,
+			UserAgent:       DefaultUserAgent,
+			Timeout:         Duration(DefaultHTTPTimeout),
+			Discover:        true,
+			DiscoverTimeout: Duration(DefaultDiscoverTimeout),
+		},
+		Cache: CacheSettings{
+			TTL:               Duration(DefaultCacheTTL),
+			AvailableDatesTTL: Duration(DefaultAvailableDatesTTL),
+			NutritionTTL:      Duration(DefaultNutritionTTL),
+		},
+		Server: ServerSettings{
+			BindAddr:            DefaultBindAddr,
+			CampusTimezone:      dates.DefaultCampusTimezone,
+			MenuRefreshInterval: Duration(DefaultMenuRefreshInterval),
+			DefaultRangeDays:    DefaultRangeDays,
+			MaxRangeDays:        DefaultMaxRangeDays,
+		},
+		Watch: WatchSettings{
+			Interval: Duration(DefaultWatchInterval),
+		},
+		Parsing: ParsingSettings{
+			DriftThreshold: DefaultDriftThreshold,
+		},
+	}
+}
+
+// LoadSettings builds settings from defaults, the JSON file at path (if path is
+// not empty) and environment variable overrides, then validates them
+func LoadSettings(path string) (*Settings, error) {
+	s := DefaultSettings()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(s); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	if err := s.applyEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// applyEnv overrides settings from environment variables
+func (s *Settings) applyEnv(getenv func(string) string) error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v := getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	duration := func(name string, dst *Duration) {
+		if v := getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			*dst = Duration(parsed)
+		}
+	}
+	integer := func(name string, dst *int) {
+		if v := getenv(name); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			*dst = parsed
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if v := getenv(name); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			*dst = parsed
+		}
+	}
+
+	str("DININGBOT_BASE_URL", &s.Site.BaseURL)
+	str("DININGBOT_USER_AGENT", &s.Site.UserAgent)
+	duration("HTTP_TIMEOUT", &s.Site.Timeout)
+	duration("MIN_REQUEST_INTERVAL", &s.Site.MinRequestInterval)
+	boolean("DISCOVER_SITE_OPTIONS", &s.Site.Discover)
+	duration("DISCOVER_TIMEOUT", &s.Site.DiscoverTimeout)
+	boolean("FETCH_NUTRITION", &s.Site.FetchNutrition)
+
+	duration("CACHE_TTL", &s.Cache.TTL)
+	duration("AVAILABLE_DATES_TTL", &s.Cache.AvailableDatesTTL)
+	duration("NUTRITION_CACHE_TTL", &s.Cache.NutritionTTL)
+
+	str("PORT", &s.Server.Port)
+	str("BIND_ADDR", &s.Server.BindAddr)
+	str("CAMPUS_TZ", &s.Server.CampusTimezone)
+	duration("MENU_REFRESH_INTERVAL", &s.Server.MenuRefreshInterval)
+	integer("RANGE_DEFAULT_DAYS", &s.Server.DefaultRangeDays)
+	integer("RANGE_MAX_DAYS", &s.Server.MaxRangeDays)
+
+	duration("WATCH_INTERVAL", &s.Watch.Interval)
+	str("WATCH_WEBHOOK_URL", &s.Watch.WebhookURL)
+	str("WATCH_SMTP_ADDR", &s.Watch.SMTP.Addr)
+	str("WATCH_SMTP_FROM", &s.Watch.SMTP.From)
+	if v := getenv("WATCH_SMTP_TO"); v != "" {
+		s.Watch.SMTP.To = strings.Split(v, ",")
+	}
+	str("WATCH_SMTP_USER", &s.Watch.SMTP.User)
+	str("WATCH_SMTP_PASSWORD", &s.Watch.SMTP.Password)
+
+	integer("PARSER_DRIFT_THRESHOLD", &s.Parsing.DriftThreshold)
+	str("PARSER_DUMP_DIR", &s.Parsing.DumpDir)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate checks that the settings are usable, reporting every problem found
+func (s *Settings) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if u, err := url.Parse(s.Site.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("site.baseUrl must be an absolute http(s) URL, got %q", s.Site.BaseURL)
+	}
+	if s.Site.UserAgent == "" {
+		add("site.userAgent must not be empty")
+	}
+	if s.Site.Timeout <= 0 {
+		add("site.timeout must be positive")
+	}
+	if s.Site.DiscoverTimeout <= 0 {
+		add("site.discoverTimeout must be positive")
+	}
+	if s.Site.MinRequestInterval < 0 {
+		add("site.minRequestInterval must not be negative")
+	}
+	if s.Cache.TTL <= 0 {
+		add("cache.ttl must be positive")
+	}
+	if s.Cache.AvailableDatesTTL <= 0 {
+		add("cache.availableDatesTtl must be positive")
+	}
+	if s.Cache.NutritionTTL <= 0 {
+		add("cache.nutritionTtl must be positive")
+	}
+
+	if s.Server.Port != "" {
+		if port, err := strconv.Atoi(s.Server.Port); err != nil || port < 1 || port > 65535 {
+			add("server.port must be a port number, got %q", s.Server.Port)
+		}
+	}
+	if s.Server.BindAddr == "" {
+		add("server.bindAddr must not be empty")
+	}
+	if _, err := time.LoadLocation(s.Server.CampusTimezone); err != nil || s.Server.CampusTimezone == "" {
+		add("server.campusTimezone must be an IANA timezone name, got %q", s.Server.CampusTimezone)
+	}
+	if s.Server.MenuRefreshInterval <= 0 {
+		add("server.menuRefreshInterval must be positive")
+	}
+	if s.Server.MaxRangeDays < 1 {
+		add("server.maxRangeDays must be at least 1")
+	}
+	if s.Server.DefaultRangeDays < 1 || s.Server.DefaultRangeDays > s.Server.MaxRangeDays {
+		add("server.defaultRangeDays must be between 1 and server.maxRangeDays")
+	}
+
+	if s.Watch.Interval <= 0 {
+		add("watch.interval must be positive")
+	}
+	if s.Watch.WebhookURL != "" {
+		if u, err := url.Parse(s.Watch.WebhookURL); err != nil || u.Scheme == "" || u.Host == "" {
+			add("watch.webhookUrl must be an absolute URL, got %q", s.Watch.WebhookURL)
+		}
+	}
+	if s.Watch.SMTP.Addr != "" && (s.Watch.SMTP.From == "" || len(s.Watch.SMTP.To) == 0) {
+		add("watch.smtp.from and watch.smtp.to are required when watch.smtp.addr is set")
+	}
+
+	if s.Parsing.DriftThreshold < 1 {
+		add("parsing.driftThreshold must be at least 1")
+	}
+	if len(s.Parsing.Rules) > 0 {
+		if _, err := parser.NewRuleSet(s.Parsing.Rules); err != nil {
+			add("parsing.rules: %w", err)
+		}
+	}
+
+	names := make(map[string]bool)
+	values := make(map[string]bool)
+	for i, loc := range s.Locations {
+		if loc.Name == "" || loc.Value == "" {
+			add("locations[%d] needs both name and value", i)
+			continue
+		}
+		if names[loc.Name] || values[loc.Value] {
+			add("locations[%d] duplicates %q", i, loc.Name)
+		}
+		names[loc.Name] = true
+		values[loc.Value] = true
+	}
+	for i, p := range s.Providers {
+		if p.Type == "" {
+			add("providers[%d].type must not be empty", i)
+		}
+	}
+	mealTypes := make(map[string]bool)
+	for i, mt := range s.MealTypes {
+		if mt == "" || mealTypes[mt] {
+			add("mealTypes[%d] is empty or duplicated", i)
+		}
+		mealTypes[mt] = true
+	}
+	errs = append(errs, s.validateServiceHours()...)
+
+	return errors.Join(errs...)
+}
+
+// Apply replaces the built-in location and meal type lists with configured ones.
+// It is not safe to call while other goroutines read the config, so call it before serving.
+func (s *Settings) Apply() {
+	if len(s.Locations) > 0 {
+		LocationMap = make(map[string]string, len(s.Locations))
+		ValidLocations = make([]string, 0, len(s.Locations))
+		for _, loc := range s.Locations {
+			LocationMap[loc.Name] = loc.Value
+			ValidLocations = append(ValidLocations, loc.Name)
+		}
+	}
+	if len(s.MealTypes) > 0 {
+		ValidMealTypes = append([]string(nil), s.MealTypes...)
+	}
+}
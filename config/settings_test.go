@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file into a temp dir and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestDefaultSettingsValid(t *testing.T) {
+	if err := DefaultSettings().Validate(); err != nil {
+		t.Errorf("DefaultSettings().Validate() error = %v", err)
+	}
+}
+
+func TestLoadSettingsFile(t *testing.T) {
+	path := writeConfig(t, `{
+		"site": {"baseUrl": "https://menus.example.edu/", "timeout": "10s", "minRequestInterval": "250ms"},
+		"cache": {"ttl": "30m"},
+		"server": {"port": "9000", "maxRangeDays": 14},
+		"locations": [{"name": "Main Hall", "value": "Main"}],
+		"mealTypes": ["Lunch", "Dinner"],
+		"parsing": {"rules": [{"name": "cards", "item": "article.dish", "fields": {"name": "h4"}}]}
+	}`)
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if s.Site.BaseURL != "https://menus.example.edu/" {
+		t.Errorf("BaseURL = %q", s.Site.BaseURL)
+	}
+	if time.Duration(s.Site.Timeout) != 10*time.Second || time.Duration(s.Site.MinRequestInterval) != 250*time.Millisecond {
+		t.Errorf("Timeout = %v, MinRequestInterval = %v", s.Site.Timeout, s.Site.MinRequestInterval)
+	}
+	if time.Duration(s.Cache.TTL) != 30*time.Minute {
+		t.Errorf("Cache.TTL = %v, want 30m", s.Cache.TTL)
+	}
+	// Unset fields keep their defaults
+	if s.Site.UserAgent != DefaultUserAgent || time.Duration(s.Cache.AvailableDatesTTL) != DefaultAvailableDatesTTL {
+		t.Error("fields missing from the file should keep their defaults")
+	}
+	if s.Server.Port != "9000" || s.Server.MaxRangeDays != 14 || s.Server.DefaultRangeDays != DefaultRangeDays {
+		t.Errorf("Server = %+v", s.Server)
+	}
+	if len(s.Locations) != 1 || s.Locations[0].Value != "Main" {
+		t.Errorf("Locations = %v", s.Locations)
+	}
+	if len(s.Parsing.Rules) != 1 || s.Parsing.Rules[0].Fields.Name != "h4" {
+		t.Errorf("Parsing.Rules = %+v", s.Parsing.Rules)
+	}
+}
+
+func TestLoadSettingsEnvOverrides(t *testing.T) {
+	path := writeConfig(t, `{"server": {"port": "9000"}}`)
+	t.Setenv("PORT", "8080")
+	t.Setenv("CACHE_TTL", "5m")
+	t.Setenv("DISCOVER_SITE_OPTIONS", "false")
+	t.Setenv("DISCOVER_TIMEOUT", "2s")
+	t.Setenv("WATCH_SMTP_ADDR", "smtp.example.com:587")
+	t.Setenv("WATCH_SMTP_FROM", "bot@example.com")
+	t.Setenv("WATCH_SMTP_TO", "a@example.com,b@example.com")
+	t.Setenv("PARSER_DUMP_DIR", "/tmp/pages")
+	t.Setenv("FETCH_NUTRITION", "true")
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if s.Server.Port != "8080" {
+		t.Errorf("Port = %q, want env override 8080", s.Server.Port)
+	}
+	if time.Duration(s.Cache.TTL) != 5*time.Minute {
+		t.Errorf("Cache.TTL = %v, want 5m", s.Cache.TTL)
+	}
+	if s.Site.Discover {
+		t.Error("Discover should be disabled by DISCOVER_SITE_OPTIONS=false")
+	}
+	if time.Duration(s.Site.DiscoverTimeout) != 2*time.Second {
+		t.Errorf("DiscoverTimeout = %v, want 2s", s.Site.DiscoverTimeout)
+	}
+	if !slices.Equal(s.Watch.SMTP.To, []string{"a@example.com", "b@example.com"}) {
+		t.Errorf("SMTP.To = %v", s.Watch.SMTP.To)
+	}
+	if !s.Site.FetchNutrition {
+		t.Error("FetchNutrition should be enabled by FETCH_NUTRITION=true")
+	}
+	if s.Parsing.DumpDir != "/tmp/pages" || s.Parsing.DriftThreshold != DefaultDriftThreshold {
+		t.Errorf("Parsing = %+v", s.Parsing)
+	}
+}
+
+func TestLoadSettingsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"malformed json", `{"site": `, nil, "invalid config file"},
+		{"unknown field", `{"sight": {}}`, nil, "unknown field"},
+		{"bad duration", `{"cache": {"ttl": "soon"}}`, nil, "invalid config file"},
+		{"relative base url", `{"site": {"baseUrl": "/menus"}}`, nil, "site.baseUrl"},
+		{"zero timeout", `{"site": {"timeout": "0s"}}`, nil, "site.timeout"},
+		{"zero discover timeout", `{"site": {"discoverTimeout": "0s"}}`, nil, "site.discoverTimeout"},
+		{"zero nutrition ttl", `{"cache": {"nutritionTtl": "0s"}}`, nil, "cache.nutritionTtl"},
+		{"bad port", `{"server": {"port": "http"}}`, nil, "server.port"},
+		{"bad timezone", `{"server": {"campusTimezone": "Mars/Olympus"}}`, nil, "server.campusTimezone"},
+		{"default above max", `{"server": {"defaultRangeDays": 10, "maxRangeDays": 5}}`, nil, "server.defaultRangeDays"},
+		{"duplicate location", `{"locations": [{"name": "A", "value": "a"}, {"name": "A", "value": "b"}]}`, nil, "duplicates"},
+		{"zero drift threshold", `{"parsing": {"driftThreshold": 0}}`, nil, "parsing.driftThreshold"},
+		{"bad parsing rule", `{"parsing": {"rules": [{"name": "cells", "item": "td["}]}}`, nil, "parsing.rules: cells"},
+		{"bad service hours", `{"serviceHours": {"Lunch": {"start": "noon", "end": "14:00"}}}`, nil, `serviceHours["Lunch"]`},
+		{"smtp without recipients", `{"watch": {"smtp": {"addr": "smtp.example.com:25"}}}`, nil, "watch.smtp"},
+		{"bad env duration", `{}`, map[string]string{"WATCH_INTERVAL": "hourly"}, "WATCH_INTERVAL"},
+		{"bad env bool", `{}`, map[string]string{"DISCOVER_SITE_OPTIONS": "nope"}, "DISCOVER_SITE_OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := LoadSettings(writeConfig(t, tt.content))
+			if err == nil {
+				t.Fatal("LoadSettings() should return error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadSettings() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadSettings(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadSettings() should return error for a missing file")
+	}
+}
+
+func TestSettingsApply(t *testing.T) {
+	restoreConfig(t)
+
+	s := DefaultSettings()
+	s.Apply()
+	if !IsValidLocation("Branner Dining") {
+		t.Error("Apply() without locations should keep the built-in list")
+	}
+
+	s.Locations = []Location{{Name: "Main Hall", Value: "Main"}}
+	s.MealTypes = []string{"Lunch"}
+	s.Apply()
+	if !slices.Equal(ValidLocations, []string{"Main Hall"}) || GetLocationValue("Main Hall") != "Main" {
+		t.Errorf("ValidLocations = %v", ValidLocations)
+	}
+	if IsValidLocation("Branner Dining") || IsValidMealType("Dinner") {
+		t.Error("configured lists should replace the built-in ones")
+	}
+}
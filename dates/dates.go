@@ -0,0 +1,253 @@
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // campus timezone must resolve even without system zoneinfo
+
+	"github.com/bklieger/diningbot/utils"
+)
+
+// DefaultCampusTimezone is the IANA timezone of the campus
+const DefaultCampusTimezone = "America/Los_Angeles"
+
+// Campus is the timezone "today" and relative expressions are evaluated in
+var Campus = mustLoadLocation(DefaultCampusTimezone)
+
+// Clock returns the current time; tests replace it to pin "now"
+var Clock = time.Now
+
+// SetCampus sets the campus timezone from an IANA name such as "America/New_York"
+func SetCampus(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid campus timezone %q: %w", name, err)
+	}
+	Campus = loc
+	return nil
+}
+
+// Now returns the current time in the campus timezone
+func Now() time.Time {
+	return Clock().In(Campus)
+}
+
+// Today returns the current campus date formatted as M/D/YYYY
+func Today() string {
+	return utils.FormatDate(Now())
+}
+
+// Range is a span of consecutive days starting at Start
+type Range struct {
+	Start time.Time
+	Days  int
+}
+
+// absoluteLayouts are the explicit date formats accepted, tried in order
+var absoluteLayouts = []string{
+	"1/2/2006",
+	"2006-01-02",
+	"2006/1/2",
+	"1-2-2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"Monday, January 2, 2006",
+	"Mon, Jan 2, 2006",
+}
+
+// yearlessLayouts are accepted without a year and resolve to the current year,
+// or to next year when that date is more than yearlessPastDays ago
+var yearlessLayouts = []string{
+	"1/2",
+	"January 2",
+	"Jan 2",
+	"2 January",
+	"2 Jan",
+}
+
+// yearlessPastDays is how far back a yearless date may be before it's taken
+// to mean next year, so "1/3" asked on 12/30 is the coming January while
+// "12/28" is still the recent past
+const yearlessPastDays = 7
+
+var (
+	inDaysPattern      = regexp.MustCompile(`^in (\d+|a|one) (day|days|week|weeks)$`)
+	daysFromNowPattern = regexp.MustCompile(`^(\d+|a|one) (day|days|week|weeks) from (now|today)$`)
+	weekdayPattern     = regexp.MustCompile(`^(this |next |coming |upcoming )?([a-z]+)$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Resolve parses a single date expressed as M/D/YYYY, ISO-8601 (YYYY-MM-DD or a
+// full timestamp), a written date ("January 15, 2025"), or a relative expression
+// ("today", "tomorrow", "next friday", "in 3 days") evaluated at now in the campus
+// timezone. Multi-day expressions such as "this weekend" resolve to their first day.
+// The result is midnight in the campus timezone.
+func Resolve(input string, now time.Time) (time.Time, error) {
+	r, err := ResolveRange(input, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.Start, nil
+}
+
+// ResolveRange is like Resolve but also understands multi-day expressions:
+// "this weekend", "next weekend", "this week" (today through Sunday) and
+// "next week" (Monday through Sunday). Single dates resolve to a one-day range.
+func ResolveRange(input string, now time.Time) (Range, error) {
+	now = now.In(Campus)
+	today := startOfDay(now)
+	s := normalize(input)
+
+	if s == "" {
+		return Range{}, fmt.Errorf("date is empty")
+	}
+
+	switch s {
+	case "today", "tonight", "now":
+		return day(today), nil
+	case "tomorrow", "tmrw", "tomorrow night":
+		return day(today.AddDate(0, 0, 1)), nil
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1)), nil
+	case "day after tomorrow", "the day after tomorrow":
+		return day(today.AddDate(0, 0, 2)), nil
+	case "weekend", "this weekend", "the weekend":
+		return weekend(today, false), nil
+	case "next weekend":
+		return weekend(today, true), nil
+	case "this week":
+		return Range{Start: today, Days: daysUntilSundayInclusive(today)}, nil
+	case "next week":
+		return Range{Start: nextWeekday(today, time.Monday, true), Days: 7}, nil
+	}
+
+	if m := inDaysPattern.FindStringSubmatch(s); m != nil {
+		return day(today.AddDate(0, 0, offsetDays(m[1], m[2]))), nil
+	}
+	if m := daysFromNowPattern.FindStringSubmatch(s); m != nil {
+		return day(today.AddDate(0, 0, offsetDays(m[1], m[2]))), nil
+	}
+	if m := weekdayPattern.FindStringSubmatch(s); m != nil {
+		if wd, ok := weekdays[m[2]]; ok {
+			strictlyAfter := strings.TrimSpace(m[1]) == "next"
+			return day(nextWeekday(today, wd, strictlyAfter)), nil
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(input)); err == nil {
+		return day(startOfDay(t.In(Campus))), nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, titleCase(s), Campus); err == nil {
+			return day(t), nil
+		}
+	}
+	for _, layout := range yearlessLayouts {
+		if t, err := time.ParseInLocation(layout, titleCase(s), Campus); err == nil {
+			resolved := time.Date(today.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Campus)
+			if resolved.Before(today.AddDate(0, 0, -yearlessPastDays)) {
+				resolved = time.Date(today.Year()+1, t.Month(), t.Day(), 0, 0, 0, 0, Campus)
+			}
+			return day(resolved), nil
+		}
+	}
+
+	return Range{}, fmt.Errorf("unrecognized date %q: use M/D/YYYY, YYYY-MM-DD, or an expression like \"tomorrow\" or \"next friday\"", input)
+}
+
+// Normalize resolves input and formats it as M/D/YYYY for the dining client
+func Normalize(input string, now time.Time) (string, error) {
+	t, err := Resolve(input, now)
+	if err != nil {
+		return "", err
+	}
+	return utils.FormatDate(t), nil
+}
+
+// normalize lowercases input, collapses whitespace and drops a leading "on"
+func normalize(input string) string {
+	s := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	s = strings.TrimPrefix(s, "on ")
+	return strings.TrimSuffix(s, ".")
+}
+
+// titleCase capitalizes each word so month and weekday names parse
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func day(t time.Time) Range {
+	return Range{Start: t, Days: 1}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextWeekday returns the next wd on or after today, or strictly after today if strictlyAfter
+func nextWeekday(today time.Time, wd time.Weekday, strictlyAfter bool) time.Time {
+	delta := (int(wd) - int(today.Weekday()) + 7) % 7
+	if delta == 0 && strictlyAfter {
+		delta = 7
+	}
+	return today.AddDate(0, 0, delta)
+}
+
+// weekend returns Saturday and Sunday of this (or next) weekend.
+// On a Sunday, "this weekend" is just today and "next weekend" is the coming one.
+func weekend(today time.Time, next bool) Range {
+	if today.Weekday() == time.Sunday {
+		if next {
+			return Range{Start: today.AddDate(0, 0, 6), Days: 2}
+		}
+		return Range{Start: today, Days: 1}
+	}
+	saturday := nextWeekday(today, time.Saturday, false)
+	if next {
+		saturday = saturday.AddDate(0, 0, 7)
+	}
+	return Range{Start: saturday, Days: 2}
+}
+
+func daysUntilSundayInclusive(today time.Time) int {
+	return (int(time.Sunday)-int(today.Weekday())+7)%7 + 1
+}
+
+func offsetDays(count, unit string) int {
+	n := 1
+	if count != "a" && count != "one" {
+		n, _ = strconv.Atoi(count)
+	}
+	if strings.HasPrefix(unit, "week") {
+		n *= 7
+	}
+	return n
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
@@ -0,0 +1,165 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+// wednesday is 3pm on Wednesday 1/15/2025 in the campus timezone
+var wednesday = time.Date(2025, 1, 15, 15, 0, 0, 0, Campus)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"existing format", "1/20/2025", "1/20/2025"},
+		{"zero padded", "01/05/2025", "1/5/2025"},
+		{"iso", "2025-01-15", "1/15/2025"},
+		{"iso timestamp", "2025-01-16T01:00:00Z", "1/15/2025"},
+		{"written", "January 20, 2025", "1/20/2025"},
+		{"written lowercase abbreviated", "jan 20 2025", "1/20/2025"},
+		{"yearless", "Feb 3", "2/3/2025"},
+		{"today", "today", "1/15/2025"},
+		{"tonight", "Tonight", "1/15/2025"},
+		{"tomorrow", " tomorrow ", "1/16/2025"},
+		{"yesterday", "yesterday", "1/14/2025"},
+		{"day after tomorrow", "day after tomorrow", "1/17/2025"},
+		{"weekday", "friday", "1/17/2025"},
+		{"weekday is today", "wednesday", "1/15/2025"},
+		{"this weekday", "this friday", "1/17/2025"},
+		{"next weekday", "next friday", "1/17/2025"},
+		{"next same weekday", "next wednesday", "1/22/2025"},
+		{"on weekday", "on Monday", "1/20/2025"},
+		{"in days", "in 3 days", "1/18/2025"},
+		{"in a week", "in a week", "1/22/2025"},
+		{"days from now", "2 days from now", "1/17/2025"},
+		{"this weekend", "this weekend", "1/18/2025"},
+		{"next week", "next week", "1/20/2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input, wednesday)
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveInvalid(t *testing.T) {
+	for _, input := range []string{"", "someday", "13/45/2025", "next blursday"} {
+		if _, err := Resolve(input, wednesday); err == nil {
+			t.Errorf("Resolve(%q) should return error", input)
+		}
+	}
+}
+
+func TestResolveYearlessNearNewYear(t *testing.T) {
+	december := time.Date(2025, 12, 30, 12, 0, 0, 0, Campus)
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1/3", "1/3/2026"},
+		{"Jan 3", "1/3/2026"},
+		{"12/31", "12/31/2025"},
+		{"12/28", "12/28/2025"},
+		{"December 1", "12/1/2026"},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.input, december)
+		if err != nil {
+			t.Fatalf("Normalize(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) on 12/30/2025 = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveUsesCampusTimezone(t *testing.T) {
+	// 5pm Pacific is already the next day in UTC
+	now := time.Date(2025, 1, 16, 1, 0, 0, 0, time.UTC)
+	got, err := Normalize("today", now)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if got != "1/15/2025" {
+		t.Errorf("Normalize(today) = %v, want 1/15/2025", got)
+	}
+
+	resolved, _ := Resolve("today", now)
+	if resolved.Location() != Campus || resolved.Hour() != 0 {
+		t.Errorf("Resolve() = %v, want midnight in campus timezone", resolved)
+	}
+}
+
+func TestResolveRange(t *testing.T) {
+	sunday := time.Date(2025, 1, 19, 12, 0, 0, 0, Campus)
+
+	tests := []struct {
+		name      string
+		input     string
+		now       time.Time
+		wantStart string
+		wantDays  int
+	}{
+		{"single date", "1/20/2025", wednesday, "1/20/2025", 1},
+		{"this weekend", "this weekend", wednesday, "1/18/2025", 2},
+		{"next weekend", "next weekend", wednesday, "1/25/2025", 2},
+		{"this weekend on sunday", "this weekend", sunday, "1/19/2025", 1},
+		{"next weekend on sunday", "next weekend", sunday, "1/25/2025", 2},
+		{"this week", "this week", wednesday, "1/15/2025", 5},
+		{"next week", "next week", wednesday, "1/20/2025", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRange(tt.input, tt.now)
+			if err != nil {
+				t.Fatalf("ResolveRange(%q) error = %v", tt.input, err)
+			}
+			if start := got.Start.Format("1/2/2006"); start != tt.wantStart || got.Days != tt.wantDays {
+				t.Errorf("ResolveRange(%q) = %s for %d days, want %s for %d days", tt.input, start, got.Days, tt.wantStart, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestNowAndTodayUseClockInCampusTimezone(t *testing.T) {
+	defer func() { Clock = time.Now }()
+
+	// 4:30pm Pacific on 1/15 is 12:30am UTC on 1/16
+	Clock = func() time.Time { return time.Date(2025, 1, 16, 0, 30, 0, 0, time.UTC) }
+
+	if got := Today(); got != "1/15/2025" {
+		t.Errorf("Today() = %v, want 1/15/2025", got)
+	}
+	if got := Now(); got.Location() != Campus || got.Hour() != 16 {
+		t.Errorf("Now() = %v, want 4:30pm in campus timezone", got)
+	}
+}
+
+func TestSetCampus(t *testing.T) {
+	defer func() { Campus = mustLoadLocation(DefaultCampusTimezone); Clock = time.Now }()
+
+	if err := SetCampus("Not/AZone"); err == nil {
+		t.Error("SetCampus() should reject unknown timezone")
+	}
+
+	if err := SetCampus("America/New_York"); err != nil {
+		t.Fatalf("SetCampus() error = %v", err)
+	}
+	// 11pm Pacific on 1/15 is already 1/16 in New York
+	Clock = func() time.Time { return time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC) }
+	if got := Today(); got != "1/16/2025" {
+		t.Errorf("Today() = %v, want 1/16/2025", got)
+	}
+}
@@ -0,0 +1,245 @@
+// Package feed writes Atom (RFC 4287) and RSS 2.0 feeds and remembers when
+// each entry's content last changed, so entries are only marked updated when
+// they really change.
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"io"
+	"sync"
+	"time"
+)
+
+// Entry is one feed entry
+type Entry struct {
+	// ID is a permanent, unique URI for the entry
+	ID    string
+	Title string
+	// Content is HTML
+	Content string
+	Link    string
+	// Updated is when the content last changed
+	Updated time.Time
+}
+
+// Feed is a list of entries
+type Feed struct {
+	// ID is a permanent, unique URI for the feed
+	ID       string
+	Title    string
+	Subtitle string
+	// Link is the page the feed is about, and Self the feed's own URL
+	Link    string
+	Self    string
+	Entries []Entry
+}
+
+// Updated is when any entry last changed, or the zero time without entries
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+	return updated
+}
+
+// Atom's and RSS's XML elements
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Content atomText  `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   string      `xml:"author>name"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      *atomLink `xml:"http://www.w3.org/2005/Atom link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// Author names the feeds' author, which Atom requires
+const Author = "diningbot"
+
+// Atom writes the feed as an Atom document. A feed without entries is
+// dated now.
+func (f Feed) Atom(w io.Writer, now time.Time) error {
+	doc := atomFeed{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  stamp(f.Updated(), now).Format(time.RFC3339),
+		Author:   Author,
+		Entries:  []atomEntry{},
+	}
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.Link, Rel: "alternate", Type: "text/html"})
+	}
+	if f.Self != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.Self, Rel: "self", Type: "application/atom+xml"})
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:      e.ID,
+			Title:   e.Title,
+			Updated: e.Updated.Format(time.RFC3339),
+			Content: atomText{Type: "html", Body: e.Content},
+		}
+		if e.Link != "" {
+			entry.Link = &atomLink{Href: e.Link, Rel: "alternate"}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return encode(w, doc)
+}
+
+// RSS writes the feed as an RSS 2.0 document, with each entry's update time
+// as its pubDate. A feed without entries is dated now.
+func (f Feed) RSS(w io.Writer, now time.Time) error {
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Subtitle,
+			LastBuildDate: stamp(f.Updated(), now).Format(time.RFC1123Z),
+			Items:         []rssItem{},
+		},
+	}
+	if f.Self != "" {
+		doc.Channel.AtomLink = &atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"}
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Description: e.Content,
+			GUID:        rssGUID{Value: e.ID},
+			PubDate:     e.Updated.Format(time.RFC1123Z),
+		})
+	}
+	return encode(w, doc)
+}
+
+// stamp returns updated, or now if updated is zero
+func stamp(updated, now time.Time) time.Time {
+	if updated.IsZero() {
+		return now
+	}
+	return updated
+}
+
+// encode writes an indented XML document with its declaration
+func encode(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Tracker remembers when each entry's content last changed. It keeps a hash
+// of the content, not the content itself. It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	entries map[string]tracked
+}
+
+type tracked struct {
+	hash    [sha256.Size]byte
+	changed time.Time
+	seen    time.Time
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{entries: make(map[string]tracked)}
+}
+
+// Changed records content for id and returns when it changed: now if the
+// content is new or differs from what was last recorded, and the earlier
+// time otherwise
+func (t *Tracker) Changed(id, content string, now time.Time) time.Time {
+	hash := sha256.Sum256([]byte(content))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[id]
+	if !ok || entry.hash != hash {
+		entry = tracked{hash: hash, changed: now}
+	}
+	entry.seen = now
+	t.entries[id] = entry
+	return entry.changed
+}
+
+// Prune forgets entries last recorded before the given time
+func (t *Tracker) Prune(before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, entry := range t.entries {
+		if entry.seen.Before(before) {
+			delete(t.entries, id)
+		}
+	}
+}
+
+// Len returns the number of entries tracked
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
@@ -0,0 +1,111 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	monday  = time.Date(2025, 1, 13, 8, 0, 0, 0, time.UTC)
+	tuesday = monday.AddDate(0, 0, 1)
+)
+
+var lunch = Feed{
+	ID:       "urn:diningbot:feed:branner-dining",
+	Title:    "Branner Dining",
+	Subtitle: "Upcoming meals",
+	Link:     "https://menus.example.edu/",
+	Self:     "http://localhost:8080/feeds/branner-dining.atom",
+	Entries: []Entry{
+		{ID: "urn:diningbot:menu:20250113-lunch-branner-dining", Title: "Lunch", Content: "<ul><li>Pho &amp; Rice</li></ul>", Updated: monday},
+		{ID: "urn:diningbot:menu:20250114-lunch-branner-dining", Title: "Lunch", Content: "<ul><li>Tacos</li></ul>", Updated: tuesday},
+	},
+}
+
+func TestAtom(t *testing.T) {
+	var b strings.Builder
+	if err := lunch.Atom(&b, time.Now()); err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+	out := b.String()
+
+	var doc atomFeed
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("Atom() wrote invalid XML: %v\n%s", err, out)
+	}
+	if doc.XMLName.Space != "http://www.w3.org/2005/Atom" || doc.ID != lunch.ID || doc.Author != Author {
+		t.Errorf("feed = %+v", doc)
+	}
+	if doc.Updated != "2025-01-14T08:00:00Z" {
+		t.Errorf("updated = %q, want the latest entry's time", doc.Updated)
+	}
+	if len(doc.Entries) != 2 || doc.Entries[0].Content.Body != lunch.Entries[0].Content || doc.Entries[0].Content.Type != "html" {
+		t.Errorf("entries = %+v", doc.Entries)
+	}
+	if !strings.Contains(out, `rel="self"`) || !strings.Contains(out, "&lt;li&gt;Pho &amp;amp; Rice") {
+		t.Errorf("unexpected feed:\n%s", out)
+	}
+}
+
+func TestRSS(t *testing.T) {
+	var b strings.Builder
+	if err := lunch.RSS(&b, time.Now()); err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+
+	out := b.String()
+
+	var doc rss
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("RSS() wrote invalid XML: %v\n%s", err, out)
+	}
+	if doc.Version != "2.0" || len(doc.Channel.Items) != 2 {
+		t.Errorf("rss = %+v", doc)
+	}
+	// Unmarshaling can't tell the channel link from the atom:link, so check the text
+	if !strings.Contains(out, "<link>"+lunch.Link+"</link>") || !strings.Contains(out, `href="`+lunch.Self+`" rel="self"`) {
+		t.Errorf("missing channel links:\n%s", out)
+	}
+	item := doc.Channel.Items[1]
+	if item.GUID.Value != lunch.Entries[1].ID || item.GUID.IsPermaLink || item.PubDate != "Tue, 14 Jan 2025 08:00:00 +0000" {
+		t.Errorf("item = %+v", item)
+	}
+}
+
+func TestEmptyFeed(t *testing.T) {
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	empty := Feed{ID: "urn:diningbot:feed:empty", Title: "Empty"}
+
+	var atom, rssOut strings.Builder
+	if err := empty.Atom(&atom, now); err != nil || !strings.Contains(atom.String(), "<updated>2025-02-01T12:00:00Z</updated>") {
+		t.Errorf("Atom() = %s, %v, want it dated now", atom.String(), err)
+	}
+	if err := empty.RSS(&rssOut, now); err != nil || !strings.Contains(rssOut.String(), "<lastBuildDate>Sat, 01 Feb 2025 12:00:00 +0000</lastBuildDate>") {
+		t.Errorf("RSS() = %s, %v, want it dated now", rssOut.String(), err)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	if got := tracker.Changed("lunch", "Pho", monday); !got.Equal(monday) {
+		t.Errorf("first Changed() = %v, want now", got)
+	}
+	if got := tracker.Changed("lunch", "Pho", tuesday); !got.Equal(monday) {
+		t.Errorf("unchanged Changed() = %v, want the first time", got)
+	}
+	later := tuesday.Add(time.Hour)
+	if got := tracker.Changed("lunch", "Pho, Rice", later); !got.Equal(later) {
+		t.Errorf("changed Changed() = %v, want now", got)
+	}
+
+	tracker.Changed("dinner", "Curry", monday)
+	tracker.Prune(tuesday)
+	if tracker.Len() != 1 {
+		t.Errorf("Len() = %d after pruning, want 1", tracker.Len())
+	}
+	if got := tracker.Changed("dinner", "Curry", later); !got.Equal(later) {
+		t.Errorf("Changed() after pruning = %v, want now", got)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/feed"
+	"github.com/bklieger/diningbot/utils"
+)
+
+// menuChanges remembers when each meal's menu last changed, dating feed entries
+var menuChanges = feed.NewTracker()
+
+// feedTrackingWindow is how long a meal's change time is kept after a feed
+// last included it
+const feedTrackingWindow = 7 * 24 * time.Hour
+
+// menuFeedRequest asks for a feed of a location's upcoming meals
+type menuFeedRequest struct {
+	location  string
+	mealTypes []string
+	days      int
+	// meals is the meals query parameter as given, naming the feed
+	meals string
+}
+
+// parseMenuFeedRequest parses a feed URL like {prefix}{location}{ext}, with
+// query parameters:
+//   - meals: comma-separated meal types (default: every meal type)
+//   - days: days to include starting today (default and limit as for get_menus_range)
+//
+// The location may be a nickname, like /ical/flomo.ics. On failure it writes
+// an error response and returns false.
+func parseMenuFeedRequest(w http.ResponseWriter, r *http.Request, prefix, ext string) (menuFeedRequest, bool) {
+	name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, prefix), ext)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return menuFeedRequest{}, false
+	}
+	location, err := config.NormalizeLocation(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return menuFeedRequest{}, false
+	}
+	req := menuFeedRequest{location: location, mealTypes: config.ValidMealTypes}
+
+	if meals := r.URL.Query().Get("meals"); meals != "" {
+		req.mealTypes = nil
+		for _, m := range strings.Split(meals, ",") {
+			mealType, err := config.NormalizeMealType(strings.TrimSpace(m))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return menuFeedRequest{}, false
+			}
+			req.mealTypes = append(req.mealTypes, mealType)
+		}
+		req.meals = strings.Join(req.mealTypes, ",")
+	}
+
+	req.days = settings.Server.DefaultRangeDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		if req.days, err = strconv.Atoi(v); err != nil || req.days < 1 {
+			http.Error(w, fmt.Sprintf("days must be a positive number, got %q", v), http.StatusBadRequest)
+			return menuFeedRequest{}, false
+		}
+	}
+	req.days = min(req.days, settings.Server.MaxRangeDays)
+
+	if err := initProviders(); err != nil {
+		http.Error(w, "Failed to initialize client: "+err.Error(), http.StatusServiceUnavailable)
+		return menuFeedRequest{}, false
+	}
+	return req, true
+}
+
+// servedMeal is a meal on an upcoming menu
+type servedMeal struct {
+	day      time.Time
+	mealType string
+	items    []string
+}
+
+// upcomingMeals fetches the meals served at a location over the requested
+// days, in order. Meals that aren't served, or whose menu can't be fetched,
+// are left out. If no menu can be fetched it returns the last error, so an
+// outage isn't published as a location with no meals.
+func upcomingMeals(req menuFeedRequest, now time.Time) ([]servedMeal, error) {
+	// Skip dates the site doesn't offer; if its date list is unavailable, try every day
+	var offered map[string]bool
+	if available, err := menuProviders.AvailableDates(req.location); err == nil {
+		offered = make(map[string]bool, len(available))
+		for _, d := range available {
+			offered[d] = true
+		}
+	}
+
+	// Only meals the location's provider serves are fetched, so an outage
+	// isn't hidden by the empty menus of meals it doesn't serve
+	mealTypes := req.mealTypes
+	if p, _, err := menuProviders.Lookup(req.location); err == nil {
+		served := p.MealTypes()
+		mealTypes = slices.DeleteFunc(slices.Clone(mealTypes), func(m string) bool { return !slices.Contains(served, m) })
+	}
+
+	var meals []servedMeal
+	fetches, failed := 0, 0
+	var lastErr error
+	for i := range req.days {
+		day := now.AddDate(0, 0, i)
+		date := utils.FormatDate(day)
+		if offered != nil && !offered[date] {
+			continue
+		}
+		for _, mealType := range mealTypes {
+			items, err := menuProviders.GetMenu(req.location, date, mealType)
+			fetches++
+			if err != nil {
+				failed++
+				lastErr = err
+				continue
+			}
+			if len(items) == 0 {
+				continue
+			}
+			meals = append(meals, servedMeal{day: day, mealType: mealType, items: items})
+		}
+	}
+	if failed > 0 && failed == fetches {
+		return nil, fmt.Errorf("Error fetching menus: %w", lastErr)
+	}
+	return meals, nil
+}
+
+// mealID identifies a meal, like "20250115-lunch-branner-dining"
+func mealID(location, mealType string, day time.Time) string {
+	return fmt.Sprintf("%s-%s-%s", day.Format("20060102"), slug(mealType), slug(location))
+}
+
+// slug lowercases s and replaces runs of other characters than letters and
+// digits with a hyphen, like "branner-dining"
+func slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// handleFeed serves /feeds/{location}.atom and /feeds/{location}.rss, with
+// an entry per upcoming meal. It takes the same query parameters as the
+// calendar feed.
+func handleFeed(w http.ResponseWriter, r *http.Request) {
+	ext := path.Ext(r.URL.Path)
+	if ext != ".atom" && ext != ".rss" {
+		http.NotFound(w, r)
+		return
+	}
+	req, ok := parseMenuFeedRequest(w, r, "/feeds/", ext)
+	if !ok {
+		return
+	}
+
+	now := dates.Now()
+	f, err := menuFeed(req, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	f.Self = requestURL(r)
+	var body bytes.Buffer
+	contentType := "application/rss+xml; charset=utf-8"
+	if ext == ".atom" {
+		contentType = "application/atom+xml; charset=utf-8"
+		err = f.Atom(&body, now)
+	} else {
+		err = f.RSS(&body, now)
+	}
+	if err != nil {
+		http.Error(w, "Error encoding feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body.Bytes())
+}
+
+// menuFeed builds a feed of a location's upcoming meals. An entry is dated
+// when its menu was first seen as it is now, so readers only see it as
+// updated when the menu changes. It fails if no menu can be fetched.
+func menuFeed(req menuFeedRequest, now time.Time) (feed.Feed, error) {
+	menuChanges.Prune(now.Add(-feedTrackingWindow))
+
+	id := "urn:diningbot:feed:" + slug(req.location)
+	title := req.location
+	if req.meals != "" {
+		id += ":" + slug(req.meals)
+		title += " " + strings.Join(req.mealTypes, ", ")
+	}
+	f := feed.Feed{
+		ID:       id,
+		Title:    title + " Menus",
+		Subtitle: fmt.Sprintf("Upcoming meals at %s", req.location),
+		Link:     settings.Site.BaseURL,
+		Entries:  []feed.Entry{},
+	}
+
+	meals, err := upcomingMeals(req, now)
+	if err != nil {
+		return feed.Feed{}, err
+	}
+	for _, meal := range meals {
+		id := "urn:diningbot:menu:" + mealID(req.location, meal.mealType, meal.day)
+		var content strings.Builder
+		content.WriteString("<ul>")
+		for _, item := range meal.items {
+			fmt.Fprintf(&content, "<li>%s</li>", html.EscapeString(item))
+		}
+		content.WriteString("</ul>")
+
+		f.Entries = append(f.Entries, feed.Entry{
+			ID:      id,
+			Title:   fmt.Sprintf("%s at %s, %s", meal.mealType, req.location, meal.day.Format("Monday, January 2")),
+			Content: content.String(),
+			Updated: menuChanges.Changed(id, strings.Join(meal.items, "\n"), now),
+		})
+	}
+	return f, nil
+}
+
+// requestURL reconstructs the URL a request was made to, honoring
+// X-Forwarded-Proto from a TLS-terminating proxy
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
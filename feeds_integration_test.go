@@ -0,0 +1,137 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/provider"
+)
+
+// useStaticMenus serves Branner Dining's lunch from items until the test ends
+func useStaticMenus(t *testing.T, date string, items []string) {
+	t.Helper()
+	static, err := provider.NewStatic(provider.StaticMenus{
+		Name:      "test",
+		Locations: []string{"Branner Dining"},
+		MealTypes: []string{"Lunch"},
+		Menus:     map[string]map[string]map[string][]string{"Branner Dining": {date: {"Lunch": items}}},
+	})
+	if err != nil {
+		t.Fatalf("NewStatic() error = %v", err)
+	}
+	registry := provider.NewRegistry()
+	registry.Register(static)
+
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+}
+
+// TestFeedErrors tests that bad feed URLs are rejected before fetching
+func TestFeedErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/feeds/branner.json", http.StatusNotFound},
+		{"/feeds/nowhere.atom", http.StatusNotFound},
+		{"/feeds/.rss", http.StatusNotFound},
+		{"/feeds/branner.rss?meals=Elevenses", http.StatusBadRequest},
+		{"/feeds/branner.atom?days=-2", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handleFeed(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.code)
+		}
+	}
+}
+
+// TestFeedFormats tests serving a hall's feed as Atom and RSS
+func TestFeedFormats(t *testing.T) {
+	useStaticMenus(t, dates.Today(), []string{"Pho", "Mac & Cheese"})
+
+	for ext, contentType := range map[string]string{".atom": "application/atom+xml", ".rss": "application/rss+xml"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/feeds/branner"+ext+"?meals=lunch&days=1", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		handleFeed(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", ext, rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, contentType) {
+			t.Errorf("%s Content-Type = %q, want %s", ext, ct, contentType)
+		}
+		body := rec.Body.String()
+		var doc struct{}
+		if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+			t.Errorf("%s is not XML: %v", ext, err)
+		}
+		for _, want := range []string{
+			"Lunch at Branner Dining",
+			"&lt;li&gt;Mac &amp;amp; Cheese&lt;/li&gt;",
+			`href="https://example.com/feeds/branner` + ext + `?meals=lunch&amp;days=1" rel="self"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s is missing %q:\n%s", ext, want, body)
+			}
+		}
+	}
+}
+
+// TestMenuFeedUpdated tests that entries are dated by when their menu last changed
+func TestMenuFeedUpdated(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, dates.Campus)
+	req := menuFeedRequest{location: "Branner Dining", mealTypes: []string{"Lunch"}, days: 1}
+
+	useStaticMenus(t, "1/15/2025", []string{"Pho"})
+	first, err := menuFeed(req, now)
+	if err != nil {
+		t.Fatalf("menuFeed() error = %v", err)
+	}
+	if len(first.Entries) != 1 || !first.Entries[0].Updated.Equal(now) {
+		t.Fatalf("entries = %+v, want one dated now", first.Entries)
+	}
+
+	// The same menu keeps its date
+	again, _ := menuFeed(req, now.Add(time.Hour))
+	if !again.Entries[0].Updated.Equal(now) || again.Entries[0].ID != first.Entries[0].ID {
+		t.Errorf("unchanged entry = %+v, want it still dated %v", again.Entries[0], now)
+	}
+
+	// A changed menu is dated when the change was seen
+	changed := now.Add(2 * time.Hour)
+	useStaticMenus(t, "1/15/2025", []string{"Pho", "Spring Rolls"})
+	updated, _ := menuFeed(req, changed)
+	if got := updated.Entries[0]; !got.Updated.Equal(changed) || !strings.Contains(got.Content, "Spring Rolls") {
+		t.Errorf("changed entry = %+v, want it dated %v", got, changed)
+	}
+}
+
+// TestFeedsUnavailable tests that the feeds are a 502 when no menu can be
+// fetched, rather than an empty feed that would drop every published meal
+func TestFeedsUnavailable(t *testing.T) {
+	registry := provider.NewRegistry()
+	registry.Register(downProvider{})
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	for _, path := range []string{"/feeds/branner.atom?days=2", "/feeds/branner.rss?days=2"} {
+		rec := httptest.NewRecorder()
+		handleFeed(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "site unreachable") {
+			t.Errorf("GET %s = %d %q, want 502 with the fetch error", path, rec.Code, rec.Body)
+		}
+	}
+}
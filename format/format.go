@@ -0,0 +1,312 @@
+// Package format renders menus as plain text, Markdown, JSON, CSV or HTML.
+// The same renderers serve the command line and the MCP tools' text content.
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Format names an output format
+type Format string
+
+// Supported formats
+const (
+	Text     Format = "text"
+	Markdown Format = "markdown"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	HTML     Format = "html"
+)
+
+// formats lists the supported formats in the order they are documented
+var formats = []Format{Text, Markdown, JSON, CSV, HTML}
+
+// aliases are other accepted names for formats
+var aliases = map[string]Format{
+	"txt":   Text,
+	"plain": Text,
+	"md":    Markdown,
+	"htm":   HTML,
+}
+
+// Names returns the names of the supported formats
+func Names() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return names
+}
+
+// Parse returns the format named by name, ignoring case and accepting
+// aliases like "md"
+func Parse(name string) (Format, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if f, ok := aliases[key]; ok {
+		return f, nil
+	}
+	if slices.Contains(formats, Format(key)) {
+		return Format(key), nil
+	}
+	return "", fmt.Errorf("unknown format %q (valid formats: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Menu is one meal at one hall on one day
+type Menu struct {
+	Location string   `json:"location"`
+	Date     string   `json:"date"`
+	MealType string   `json:"mealType"`
+	Items    []string `json:"items"`
+}
+
+// FromRange converts menus keyed by M/D/YYYY date, as get_menus_range
+// returns them, to a list in calendar order
+func FromRange(location, mealType string, menus map[string][]string) []Menu {
+	dates := make([]string, 0, len(menus))
+	for date := range menus {
+		dates = append(dates, date)
+	}
+	slices.SortFunc(dates, compareDates)
+
+	result := make([]Menu, len(dates))
+	for i, date := range dates {
+		result[i] = Menu{Location: location, Date: date, MealType: mealType, Items: menus[date]}
+	}
+	return result
+}
+
+// compareDates orders M/D/YYYY dates, falling back to string order
+func compareDates(a, b string) int {
+	ta, errA := time.Parse("1/2/2006", a)
+	tb, errB := time.Parse("1/2/2006", b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return ta.Compare(tb)
+}
+
+// Render renders menus in the given format, in the order given. Menus may
+// be a single menu, one hall's meal over several days or several halls'
+// menus for one meal: fields the menus share make the title, and fields that
+// differ label each menu. JSON renders a single menu as an object and
+// several as an array.
+func Render(f Format, menus []Menu) (string, error) {
+	switch f {
+	case Text:
+		return renderText(menus), nil
+	case Markdown:
+		return renderMarkdown(menus), nil
+	case JSON:
+		return renderJSON(menus)
+	case CSV:
+		return renderCSV(menus)
+	case HTML:
+		return renderHTML(menus), nil
+	}
+	return "", fmt.Errorf("unknown format %q", f)
+}
+
+// field is a menu field shown in titles, labels and columns
+type field struct {
+	name  string
+	value func(Menu) string
+}
+
+// fields are in the order they are shown
+var fields = []field{
+	{"Location", func(m Menu) string { return m.Location }},
+	{"Meal", func(m Menu) string { return m.MealType }},
+	{"Date", func(m Menu) string { return m.Date }},
+}
+
+// layout splits fields into those every menu shares and those that differ
+func layout(menus []Menu) (shared, varying []field) {
+	for _, f := range fields {
+		same := true
+		for _, m := range menus[1:] {
+			same = same && f.value(m) == f.value(menus[0])
+		}
+		if same {
+			shared = append(shared, f)
+		} else {
+			varying = append(varying, f)
+		}
+	}
+	return shared, varying
+}
+
+// join joins a menu's values of fields, like "Lunch, 1/15/2025"
+func join(m Menu, fs []field) string {
+	values := make([]string, 0, len(fs))
+	for _, f := range fs {
+		if v := f.value(m); v != "" {
+			values = append(values, v)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// noMenus is rendered when there are no menus at all
+const noMenus = "No menus"
+
+// noItems stands for an empty menu
+const noItems = "(no items)"
+
+func renderText(menus []Menu) string {
+	if len(menus) == 0 {
+		return noMenus + "\n"
+	}
+	shared, varying := layout(menus)
+
+	var b strings.Builder
+	if title := join(menus[0], shared); title != "" {
+		fmt.Fprintln(&b, title)
+	}
+	for _, m := range menus {
+		if len(varying) > 0 {
+			fmt.Fprintln(&b, join(m, varying))
+		}
+		if len(m.Items) == 0 {
+			fmt.Fprintf(&b, "  %s\n", noItems)
+		}
+		for _, item := range m.Items {
+			fmt.Fprintf(&b, "  %s\n", item)
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// renderMarkdown renders a table with a row per item, labeled by the fields
+// that differ between menus on each menu's first row
+func renderMarkdown(menus []Menu) string {
+	if len(menus) == 0 {
+		return "_" + noMenus + "_\n"
+	}
+	shared, varying := layout(menus)
+
+	var b strings.Builder
+	if title := join(menus[0], shared); title != "" {
+		fmt.Fprintf(&b, "## %s\n\n", markdownCell(title))
+	}
+	b.WriteString("|")
+	for _, f := range varying {
+		fmt.Fprintf(&b, " %s |", f.name)
+	}
+	b.WriteString(" Item |\n|")
+	for range len(varying) + 1 {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for _, m := range menus {
+		items := m.Items
+		if len(items) == 0 {
+			items = []string{"_" + noItems + "_"}
+		}
+		for i, item := range items {
+			b.WriteString("|")
+			for _, f := range varying {
+				label := ""
+				if i == 0 {
+					label = markdownCell(f.value(m))
+				}
+				fmt.Fprintf(&b, " %s |", label)
+			}
+			fmt.Fprintf(&b, " %s |\n", markdownCell(item))
+		}
+	}
+	return b.String()
+}
+
+func renderJSON(menus []Menu) (string, error) {
+	// Never render null item lists
+	normalized := make([]Menu, len(menus))
+	for i, m := range menus {
+		if m.Items == nil {
+			m.Items = []string{}
+		}
+		normalized[i] = m
+	}
+
+	var v any = normalized
+	if len(normalized) == 1 {
+		v = normalized[0]
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// renderCSV renders a header and a row per item; an empty menu gets one row
+// with an empty item, so closed meals still show up
+func renderCSV(menus []Menu) (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"location", "mealType", "date", "item"})
+	for _, m := range menus {
+		items := m.Items
+		if len(items) == 0 {
+			items = []string{""}
+		}
+		for _, item := range items {
+			w.Write([]string{m.Location, m.MealType, m.Date, item})
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+// renderHTML renders a fragment like the Markdown table, with each menu's
+// labels spanning its rows
+func renderHTML(menus []Menu) string {
+	if len(menus) == 0 {
+		return "<p>" + noMenus + "</p>\n"
+	}
+	shared, varying := layout(menus)
+
+	var b strings.Builder
+	b.WriteString("<section class=\"menu\">\n")
+	if title := join(menus[0], shared); title != "" {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(title))
+	}
+	b.WriteString("<table>\n<thead><tr>")
+	for _, f := range varying {
+		fmt.Fprintf(&b, "<th>%s</th>", f.name)
+	}
+	b.WriteString("<th>Item</th></tr></thead>\n<tbody>\n")
+
+	for _, m := range menus {
+		items := make([]string, len(m.Items))
+		for i, item := range m.Items {
+			items[i] = html.EscapeString(item)
+		}
+		if len(items) == 0 {
+			items = []string{"<em>" + noItems + "</em>"}
+		}
+		for i, item := range items {
+			b.WriteString("<tr>")
+			if i == 0 {
+				for _, f := range varying {
+					fmt.Fprintf(&b, "<th rowspan=\"%d\">%s</th>", len(items), html.EscapeString(f.value(m)))
+				}
+			}
+			fmt.Fprintf(&b, "<td>%s</td></tr>\n", item)
+		}
+	}
+	b.WriteString("</tbody>\n</table>\n</section>\n")
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package format
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var lunch = Menu{Location: "Branner Dining", Date: "1/15/2025", MealType: "Lunch", Items: []string{"Pho", "Fish | Chips"}}
+
+var week = FromRange("Branner Dining", "Lunch", map[string][]string{
+	"1/10/2025": {"Tacos"},
+	"1/9/2025":  {"Pho", "Rice"},
+	"1/11/2025": {},
+})
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]Format{
+		"text":     Text,
+		"Markdown": Markdown,
+		" md ":     Markdown,
+		"JSON":     JSON,
+		"csv":      CSV,
+		"htm":      HTML,
+	} {
+		if got, err := Parse(input); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := Parse("yaml"); err == nil || !strings.Contains(err.Error(), "markdown") {
+		t.Errorf("Parse(yaml) error = %v, want one listing the valid formats", err)
+	}
+	for _, name := range Names() {
+		if _, err := Parse(name); err != nil {
+			t.Errorf("Parse(%q) error = %v", name, err)
+		}
+	}
+}
+
+func TestFromRange(t *testing.T) {
+	var dates []string
+	for _, m := range week {
+		dates = append(dates, m.Date)
+	}
+	if want := []string{"1/9/2025", "1/10/2025", "1/11/2025"}; !reflect.DeepEqual(dates, want) {
+		t.Errorf("dates = %v, want %v", dates, want)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	got, _ := Render(Text, []Menu{lunch})
+	want := "Branner Dining, Lunch, 1/15/2025\n  Pho\n  Fish | Chips\n"
+	if got != want {
+		t.Errorf("single menu:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, _ = Render(Text, week)
+	want = "Branner Dining, Lunch\n1/9/2025\n  Pho\n  Rice\n1/10/2025\n  Tacos\n1/11/2025\n  (no items)\n"
+	if got != want {
+		t.Errorf("range:\n%s\nwant:\n%s", got, want)
+	}
+
+	halls := []Menu{lunch, {Location: "Wilbur Dining", Date: "1/15/2025", MealType: "Lunch", Items: []string{"Curry"}}}
+	got, _ = Render(Text, halls)
+	if !strings.HasPrefix(got, "Lunch, 1/15/2025\nBranner Dining\n") || !strings.Contains(got, "Wilbur Dining\n  Curry\n") {
+		t.Errorf("all halls:\n%s", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	got, _ := Render(Markdown, []Menu{lunch})
+	want := "## Branner Dining, Lunch, 1/15/2025\n\n| Item |\n|---|\n| Pho |\n| Fish \\| Chips |\n"
+	if got != want {
+		t.Errorf("single menu:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, _ = Render(Markdown, week)
+	for _, line := range []string{"| Date | Item |", "| 1/9/2025 | Pho |", "|  | Rice |", "| 1/11/2025 | _(no items)_ |"} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("range is missing %q:\n%s", line, got)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	got, _ := Render(JSON, []Menu{lunch})
+	var single Menu
+	if err := json.Unmarshal([]byte(got), &single); err != nil || !reflect.DeepEqual(single, lunch) {
+		t.Errorf("single menu = %s, %v", got, err)
+	}
+
+	got, _ = Render(JSON, week)
+	var several []Menu
+	if err := json.Unmarshal([]byte(got), &several); err != nil || len(several) != 3 {
+		t.Errorf("range = %s, %v", got, err)
+	}
+	if !strings.Contains(got, `"items": []`) {
+		t.Errorf("empty menus should have an empty item list:\n%s", got)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	got, _ := Render(CSV, append([]Menu{lunch}, week[2]))
+	want := "location,mealType,date,item\n" +
+		"Branner Dining,Lunch,1/15/2025,Pho\n" +
+		"Branner Dining,Lunch,1/15/2025,Fish | Chips\n" +
+		"Branner Dining,Lunch,1/11/2025,\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	menu := lunch
+	menu.Items = []string{"Mac & Cheese", "<script>"}
+	got, _ := Render(HTML, []Menu{menu})
+	for _, want := range []string{"<h2>Branner Dining, Lunch, 1/15/2025</h2>", "<td>Mac &amp; Cheese</td>", "<td>&lt;script&gt;</td>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+
+	got, _ = Render(HTML, week)
+	if !strings.Contains(got, `<th rowspan="2">1/9/2025</th><td>Pho</td>`) || !strings.Contains(got, "<em>(no items)</em>") {
+		t.Errorf("range:\n%s", got)
+	}
+}
+
+func TestRenderNoMenus(t *testing.T) {
+	for _, f := range formats {
+		got, err := Render(f, nil)
+		if err != nil || got == "" {
+			t.Errorf("Render(%s, nil) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := Render("yaml", nil); err == nil {
+		t.Error("Render() should reject unknown formats")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bklieger/diningbot/health"
+	"github.com/bklieger/diningbot/tools"
+)
+
+// Parser health, updated by the Stanford client as it parses menu pages
+var parserHealth *health.ParserMonitor
+
+// currentParserHealth reports parser health, or unknown before the client
+// exists. It doesn't create the client: checking health shouldn't start scraping.
+func currentParserHealth() health.ParserStatus {
+	return parserHealth.Status()
+}
+
+// HealthResponse is the body of the /health endpoint
+type HealthResponse struct {
+	Status       string              `json:"status"`
+	ParserHealth health.ParserStatus `json:"parser_health"`
+	// Tools counts tool calls since the server started, by tool name
+	Tools map[string]tools.Stats `json:"tools"`
+}
+
+// handleHealth serves /health. The server is up if it answers, so the
+// status code is 200 even when the parser reports drift.
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HealthResponse{
+		Status:       "ok",
+		ParserHealth: currentParserHealth(),
+		Tools:        toolEnv.Metrics.Snapshot(),
+	})
+}
@@ -0,0 +1,202 @@
+package health
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bklieger/diningbot/parser"
+)
+
+// Parser health states
+const (
+	// StatusUnknown means no menu page has been checked yet
+	StatusUnknown = "unknown"
+	// StatusOK means the latest menu page had items in the menu container
+	StatusOK = "ok"
+	// StatusDegraded means the menu container is missing and items only come
+	// from the fallback rules
+	StatusDegraded = "degraded"
+	// StatusDrift means several halls returned valid pages without any items,
+	// which usually means the site's markup changed
+	StatusDrift = "drift"
+)
+
+// Page is a parsed menu page, as reported to a ParserMonitor
+type Page struct {
+	Location string
+	Date     string
+	MealType string
+	HTML     string
+	// ViewState reports whether the page had the ASP.NET form state, i.e. it
+	// is a real menu page rather than an error page
+	ViewState bool
+	// Expected reports whether the page listed the date, so a menu was expected
+	Expected   bool
+	Extraction parser.Extraction
+}
+
+// ParserStatus reports whether menu pages are still being parsed
+type ParserStatus struct {
+	Status       string `json:"status"`
+	Message      string `json:"message"`
+	PagesChecked int    `json:"pagesChecked"`
+	// LastSuccess is when a page last had items (RFC 3339)
+	LastSuccess string `json:"lastSuccess,omitempty"`
+	// EmptyLocations are the halls whose pages had no items since the last success
+	EmptyLocations []string `json:"emptyLocations"`
+	// DriftSince is when drift was detected (RFC 3339)
+	DriftSince string `json:"driftSince,omitempty"`
+	// Structure summarizes the page that triggered drift or degradation
+	Structure *parser.Structure `json:"structure,omitempty"`
+	// DumpPath is where that page was saved, if a dump directory is set
+	DumpPath string `json:"dumpPath,omitempty"`
+}
+
+// ParserMonitor watches parse results for signs the menu site's markup changed.
+// One empty menu is normal (a closed hall), so drift is flagged only once pages
+// from Threshold different halls had a ViewState and a listed date but no items,
+// other than closed meals, with no page having items in between.
+type ParserMonitor struct {
+	// Threshold is how many halls must return empty pages to flag drift
+	Threshold int
+	// DumpDir, if set, receives the HTML of the page that triggered drift or degradation
+	DumpDir string
+	// Now returns the current time
+	Now func() time.Time
+
+	mu          sync.Mutex
+	pages       int
+	lastSuccess time.Time
+	empty       map[string]bool
+	fallback    bool
+	driftSince  time.Time
+	structure   *parser.Structure
+	dumpPath    string
+}
+
+// NewParserMonitor creates a monitor flagging drift after threshold empty halls
+func NewParserMonitor(threshold int, dumpDir string) *ParserMonitor {
+	return &ParserMonitor{
+		Threshold: threshold,
+		DumpDir:   dumpDir,
+		Now:       time.Now,
+		empty:     make(map[string]bool),
+	}
+}
+
+// Record updates the monitor with a parsed menu page. Pages without a
+// ViewState are ignored: they are failed requests, not markup changes. Pages
+// whose menu container is empty or shows the site's no-menu notice are closed
+// meals, as on holidays and weekends, and don't count toward drift; a
+// container with content but no recognized items does.
+func (m *ParserMonitor) Record(page Page) {
+	if !page.ViewState {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pages++
+
+	if len(page.Extraction.Items) > 0 {
+		if !m.driftSince.IsZero() {
+			log.Printf("parser health: menu items found again at %s after drift", page.Location)
+		}
+		m.lastSuccess = m.Now()
+		clear(m.empty)
+		m.driftSince = time.Time{}
+
+		wasFallback := m.fallback
+		m.fallback = page.Extraction.Fallback
+		switch {
+		case m.fallback && !wasFallback:
+			m.capture(page)
+			log.Printf("parser health: menu container missing at %s, using fallback rules; page structure: %s", page.Location, m.structure)
+		case !m.fallback:
+			m.structure, m.dumpPath = nil, ""
+		}
+		return
+	}
+
+	if !page.Expected || page.Extraction.Closed {
+		return
+	}
+	m.empty[page.Location] = true
+	if len(m.empty) >= max(m.Threshold, 1) && m.driftSince.IsZero() {
+		m.driftSince = m.Now()
+		m.capture(page)
+		log.Printf("parser health: possible markup drift, %d halls returned menu pages without items (%s); page structure: %s",
+			len(m.empty), page.Location, m.structure)
+	}
+}
+
+// capture fingerprints a page and saves it if a dump directory is set;
+// callers must hold m.mu
+func (m *ParserMonitor) capture(page Page) {
+	structure := parser.Fingerprint(page.HTML)
+	m.structure = &structure
+	m.dumpPath = ""
+	if m.DumpDir == "" {
+		return
+	}
+
+	name := fmt.Sprintf("menu-%s-%s-%s-%s.html", m.Now().Format("20060102T150405"), page.Location, page.Date, page.MealType)
+	path := filepath.Join(m.DumpDir, unsafeFileChars.ReplaceAllString(name, "_"))
+	if err := os.MkdirAll(m.DumpDir, 0755); err != nil {
+		log.Printf("parser health: failed to create dump directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(page.HTML), 0644); err != nil {
+		log.Printf("parser health: failed to save page: %v", err)
+		return
+	}
+	m.dumpPath = path
+	log.Printf("parser health: saved page to %s", path)
+}
+
+// unsafeFileChars are replaced in dump file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Status reports the monitor's current view of parser health. A nil monitor,
+// as before any client exists, has checked no pages.
+func (m *ParserMonitor) Status() ParserStatus {
+	if m == nil {
+		return ParserStatus{Status: StatusUnknown, Message: "No menu pages checked yet", EmptyLocations: []string{}}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := ParserStatus{
+		PagesChecked:   m.pages,
+		EmptyLocations: append([]string{}, slices.Sorted(maps.Keys(m.empty))...),
+		Structure:      m.structure,
+		DumpPath:       m.dumpPath,
+	}
+	if !m.lastSuccess.IsZero() {
+		status.LastSuccess = m.lastSuccess.Format(time.RFC3339)
+	}
+
+	switch {
+	case m.pages == 0:
+		status.Status = StatusUnknown
+		status.Message = "No menu pages checked yet"
+	case !m.driftSince.IsZero():
+		status.Status = StatusDrift
+		status.DriftSince = m.driftSince.Format(time.RFC3339)
+		status.Message = fmt.Sprintf("%d halls returned menu pages without items; the menu site's markup may have changed", len(m.empty))
+	case m.fallback:
+		status.Status = StatusDegraded
+		status.Message = "Menu container not found; items come from fallback rules"
+	default:
+		status.Status = StatusOK
+		status.Message = "Menu pages are being parsed"
+	}
+	return status
+}
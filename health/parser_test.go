@@ -0,0 +1,159 @@
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/parser"
+)
+
+// menuPage builds a page with a ViewState and a listed date, as the menu site returns
+func menuPage(location string, items ...string) Page {
+	extraction := parser.Extraction{Container: true}
+	for _, name := range items {
+		extraction.Items = append(extraction.Items, parser.Item{Name: name, Rule: "menu-item", Confidence: 1})
+	}
+	return Page{
+		Location:   location,
+		Date:       "11/4/2024",
+		MealType:   "Lunch",
+		HTML:       `<div id="MainContent_divMenu"><p>No items</p></div>`,
+		ViewState:  true,
+		Expected:   true,
+		Extraction: extraction,
+	}
+}
+
+func newTestMonitor(threshold int, dumpDir string) *ParserMonitor {
+	m := NewParserMonitor(threshold, dumpDir)
+	m.Now = func() time.Time { return time.Date(2024, 11, 4, 12, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func TestParserMonitorUnknown(t *testing.T) {
+	status := newTestMonitor(3, "").Status()
+	if status.Status != StatusUnknown || status.EmptyLocations == nil {
+		t.Errorf("Status() = %+v, want unknown with an empty location list", status)
+	}
+}
+
+func TestParserMonitorNil(t *testing.T) {
+	var m *ParserMonitor
+	if status := m.Status(); status.Status != StatusUnknown || status.EmptyLocations == nil {
+		t.Errorf("Status() = %+v, want unknown with an empty location list", status)
+	}
+}
+
+func TestParserMonitorDrift(t *testing.T) {
+	m := newTestMonitor(3, "")
+	m.Record(menuPage("Arrillaga Family Dining Commons", "Oatmeal"))
+
+	m.Record(menuPage("Branner Dining"))
+	m.Record(menuPage("Branner Dining"))
+	m.Record(menuPage("Wilbur Dining"))
+	if status := m.Status(); status.Status != StatusOK {
+		t.Fatalf("Status() = %q after empty menus at two halls, want ok", status.Status)
+	}
+
+	m.Record(menuPage("Lakeside Dining"))
+	status := m.Status()
+	if status.Status != StatusDrift {
+		t.Fatalf("Status() = %q after empty menus at three halls, want drift", status.Status)
+	}
+	want := []string{"Branner Dining", "Lakeside Dining", "Wilbur Dining"}
+	if !reflect.DeepEqual(status.EmptyLocations, want) {
+		t.Errorf("EmptyLocations = %v, want %v", status.EmptyLocations, want)
+	}
+	if status.Structure == nil || status.Structure.Hash == "" || status.DriftSince == "" {
+		t.Errorf("drift should record the page structure and time, got %+v", status)
+	}
+
+	m.Record(menuPage("Wilbur Dining", "Minestrone"))
+	if status := m.Status(); status.Status != StatusOK || len(status.EmptyLocations) != 0 || status.Structure != nil {
+		t.Errorf("Status() = %+v after items were found again, want ok", status)
+	}
+}
+
+func TestParserMonitorIgnoresUnexpectedPages(t *testing.T) {
+	m := newTestMonitor(2, "")
+
+	errorPage := menuPage("Branner Dining")
+	errorPage.ViewState = false
+	m.Record(errorPage)
+	if status := m.Status(); status.PagesChecked != 0 {
+		t.Errorf("PagesChecked = %d, pages without a ViewState should be ignored", status.PagesChecked)
+	}
+
+	unlisted := menuPage("Wilbur Dining")
+	unlisted.Expected = false
+	m.Record(unlisted)
+	m.Record(menuPage("Branner Dining"))
+	if status := m.Status(); status.Status != StatusOK || len(status.EmptyLocations) != 1 {
+		t.Errorf("Status() = %+v, an empty menu for an unlisted date is not drift", status)
+	}
+}
+
+// parsedPage builds a page like menuPage from HTML parsed with the default rules
+func parsedPage(location, html string) Page {
+	page := menuPage(location)
+	page.HTML = html
+	page.Extraction = parser.DefaultRuleSet().Extract(html, false)
+	return page
+}
+
+func TestParserMonitorClosedMeals(t *testing.T) {
+	m := newTestMonitor(2, "")
+	m.Record(parsedPage("Branner Dining", `<div id="MainContent_divMenu"></div>`))
+	m.Record(parsedPage("Wilbur Dining", `<div id="MainContent_divMenu"><span class="clsNoMenu">No menu available</span></div>`))
+	m.Record(parsedPage("Lakeside Dining", `<div id="MainContent_divMenu"> </div>`))
+	if status := m.Status(); status.Status == StatusDrift || len(status.EmptyLocations) != 0 {
+		t.Errorf("Status() = %+v, empty menu containers are closed meals, not drift", status)
+	}
+}
+
+func TestParserMonitorRenamedItems(t *testing.T) {
+	m := newTestMonitor(2, "")
+	renamed := `<div id="MainContent_divMenu"><ul><li class="clsDish"><span class="clsDish_Title">Pho</span></li></ul></div>`
+	m.Record(parsedPage("Branner Dining", renamed))
+	m.Record(parsedPage("Wilbur Dining", renamed))
+	if status := m.Status(); status.Status != StatusDrift {
+		t.Errorf("Status() = %+v, a menu container with unrecognized items is drift", status)
+	}
+}
+
+func TestParserMonitorDegraded(t *testing.T) {
+	m := newTestMonitor(3, "")
+	page := menuPage("Branner Dining", "Pancakes")
+	page.Extraction.Container = false
+	page.Extraction.Fallback = true
+	m.Record(page)
+
+	status := m.Status()
+	if status.Status != StatusDegraded || status.Structure == nil {
+		t.Errorf("Status() = %+v, want degraded with the page structure", status)
+	}
+}
+
+func TestParserMonitorDump(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pages")
+	m := newTestMonitor(1, dir)
+	m.Record(menuPage("Branner Dining"))
+
+	status := m.Status()
+	if status.DumpPath == "" {
+		t.Fatal("DumpPath should be set when a dump directory is configured")
+	}
+	if filepath.Dir(status.DumpPath) != dir || filepath.Base(status.DumpPath) != "menu-20241104T120000-Branner_Dining-11_4_2024-Lunch.html" {
+		t.Errorf("DumpPath = %q", status.DumpPath)
+	}
+	data, err := os.ReadFile(status.DumpPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != menuPage("").HTML {
+		t.Errorf("saved page = %q", data)
+	}
+}
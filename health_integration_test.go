@@ -0,0 +1,34 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bklieger/diningbot/health"
+)
+
+// TestHealthBeforeClient tests that /health reports unknown parser health
+// before the client exists, without creating it
+func TestHealthBeforeClient(t *testing.T) {
+	savedClient, savedHealth := diningClient, parserHealth
+	diningClient, parserHealth = nil, nil
+	t.Cleanup(func() { diningClient, parserHealth = savedClient, savedHealth })
+
+	rec := httptest.NewRecorder()
+	handleHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var body HealthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to parse %q: %v", rec.Body, err)
+	}
+	if body.ParserHealth.Status != health.StatusUnknown {
+		t.Errorf("parser_health = %+v, want unknown", body.ParserHealth)
+	}
+	if diningClient != nil || parserHealth != nil {
+		t.Error("/health created the client")
+	}
+}
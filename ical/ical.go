@@ -0,0 +1,123 @@
+// Package ical writes iCalendar (RFC 5545) feeds, enough of the format for
+// calendar apps to subscribe to menus: a calendar of timed or all-day events.
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// ProductID identifies the program that made a calendar
+const ProductID = "-//diningbot//Dining Hall Menus//EN"
+
+// maxLineOctets is the longest content line allowed before folding
+const maxLineOctets = 75
+
+// Event is a calendar event. Calendar apps match events by UID, so an event
+// published again with the same UID replaces the earlier copy.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	// AllDay makes an event span Start's date; End is ignored
+	AllDay bool
+	// URL links to the event's source, if any
+	URL string
+}
+
+// Calendar is a feed of events
+type Calendar struct {
+	// Name is shown by calendar apps that support X-WR-CALNAME
+	Name   string
+	Events []Event
+	// Stamp is when the feed was generated, written as each event's DTSTAMP
+	Stamp time.Time
+}
+
+// Encode writes the calendar with CRLF line endings and lines folded at 75 octets
+func (c Calendar) Encode(w io.Writer) error {
+	e := &encoder{w: w}
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProductID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, event := range c.Events {
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", escape(event.UID))
+		e.line("DTSTAMP", utc(c.Stamp))
+		if event.AllDay {
+			e.line("DTSTART;VALUE=DATE", event.Start.Format("20060102"))
+			e.line("DTEND;VALUE=DATE", event.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			e.line("DTSTART", utc(event.Start))
+			e.line("DTEND", utc(event.End))
+		}
+		e.line("SUMMARY", escape(event.Summary))
+		if event.Location != "" {
+			e.line("LOCATION", escape(event.Location))
+		}
+		if event.Description != "" {
+			e.line("DESCRIPTION", escape(event.Description))
+		}
+		if event.URL != "" {
+			e.line("URL", event.URL)
+		}
+		e.line("TRANSP", "TRANSPARENT")
+		e.line("END", "VEVENT")
+	}
+	e.line("END", "VCALENDAR")
+	return e.err
+}
+
+// utc formats a time as a UTC date-time, like 20250115T190000Z
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// encoder writes content lines, keeping the first write error
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+// line writes "name:value", folding it into lines of at most 75 octets
+// without splitting a UTF-8 character
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	var b strings.Builder
+	length := 0
+	for _, r := range name + ":" + value {
+		size := len(string(r))
+		if length+size > maxLineOctets {
+			// Continuation lines start with a space, which counts toward the limit
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	b.WriteString("\r\n")
+	_, e.err = io.WriteString(e.w, b.String())
+}
@@ -0,0 +1,98 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func encode(t *testing.T, c Calendar) string {
+	t.Helper()
+	var b strings.Builder
+	if err := c.Encode(&b); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	return b.String()
+}
+
+// unfold joins folded lines and splits the calendar into content lines
+func unfold(s string) []string {
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+func TestEncode(t *testing.T) {
+	campus, _ := time.LoadLocation("America/Los_Angeles")
+	c := Calendar{
+		Name:  "Branner Dining",
+		Stamp: time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID:         "20250115-lunch-branner@diningbot",
+				Summary:     "Lunch at Branner Dining",
+				Description: "Pho\nRice, steamed; plain",
+				Location:    "Branner Dining",
+				Start:       time.Date(2025, 1, 15, 11, 0, 0, 0, campus),
+				End:         time.Date(2025, 1, 15, 14, 0, 0, 0, campus),
+			},
+			{
+				UID:     "20250116-late-night-branner@diningbot",
+				Summary: "Late Night at Branner Dining",
+				Start:   time.Date(2025, 1, 16, 0, 0, 0, 0, campus),
+				AllDay:  true,
+			},
+		},
+	}
+	lines := unfold(encode(t, c))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ProductID,
+		"X-WR-CALNAME:Branner Dining",
+		"UID:20250115-lunch-branner@diningbot",
+		"DTSTAMP:20250115T080000Z",
+		"DTSTART:20250115T190000Z",
+		"DTEND:20250115T220000Z",
+		`DESCRIPTION:Pho\nRice\, steamed\; plain`,
+		"DTSTART;VALUE=DATE:20250116",
+		"DTEND;VALUE=DATE:20250117",
+		"END:VCALENDAR",
+	} {
+		found := false
+		for _, line := range lines {
+			found = found || line == want
+		}
+		if !found {
+			t.Errorf("missing line %q in:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("calendar isn't wrapped in VCALENDAR: %q ... %q", lines[0], lines[len(lines)-1])
+	}
+	if n := strings.Count(strings.Join(lines, "\n"), "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("%d events, want 2", n)
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	description := strings.Repeat("Crème brûlée, ", 20)
+	out := encode(t, Calendar{Events: []Event{{UID: "a", Summary: "Dinner", Description: description, AllDay: true}}})
+
+	if !strings.HasSuffix(out, "\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("lines must end in CRLF")
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a character: %q", line)
+		}
+	}
+	for _, line := range unfold(out) {
+		if strings.HasPrefix(line, "DESCRIPTION:") && line != "DESCRIPTION:"+escape(description) {
+			t.Errorf("unfolded description = %q", line)
+		}
+	}
+}
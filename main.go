@@ -2,24 +2,61 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/smtp"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/bklieger/diningbot/client"
 	"github.com/bklieger/diningbot/config"
-	"github.com/bklieger/diningbot/utils"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/health"
+	"github.com/bklieger/diningbot/provider"
+	"github.com/bklieger/diningbot/tools"
+	"github.com/bklieger/diningbot/watchlist"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Runtime settings, loaded in main from the config file and environment
+var settings = config.DefaultSettings()
+
 // Global client instance (initialized once)
 var diningClient *client.DiningHallClient
 
+// Menu providers routed by location; the Stanford client is always registered first
+var menuProviders *provider.Registry
+
+// Resource subscriptions refreshed in the background
+var menuSubs = newMenuSubscriptions()
+
+// Watchlist state shared by the watch tools and the background scanner
+var (
+	watchStore   = watchlist.NewStore()
+	watchScanner *watchlist.Scanner
+)
+
 func initClient() error {
 	if diningClient == nil {
+		if parserHealth == nil {
+			parserHealth = health.NewParserMonitor(settings.Parsing.DriftThreshold, settings.Parsing.DumpDir)
+		}
 		var err error
-		diningClient, err = client.NewDiningHallClient()
+		diningClient, err = client.NewDiningHallClientWithConfig(client.Config{
+			BaseURL:            settings.Site.BaseURL,
+			UserAgent:          settings.Site.UserAgent,
+			Timeout:            time.Duration(settings.Site.Timeout),
+			CacheTTL:           time.Duration(settings.Cache.TTL),
+			AvailableDatesTTL:  time.Duration(settings.Cache.AvailableDatesTTL),
+			MinRequestInterval: time.Duration(settings.Site.MinRequestInterval),
+			ParseRules:         settings.Parsing.Rules,
+			Monitor:            parserHealth,
+			FetchNutrition:     settings.Site.FetchNutrition,
+			NutritionTTL:       time.Duration(settings.Cache.NutritionTTL),
+		})
 		if err != nil {
 			return err
 		}
@@ -27,296 +64,185 @@ func initClient() error {
 	return nil
 }
 
-// GetMenuInput defines the input for the get_menu tool
-type GetMenuInput struct {
-	Location string `json:"location" jsonschema:"required,description=The dining hall location name"`
-	Date     string `json:"date" jsonschema:"description=Date in M/D/YYYY format (e.g., 1/15/2025). If not provided, uses today's date"`
-	MealType string `json:"mealType" jsonschema:"required,description=The meal type"`
-}
-
-// GetMenuOutput defines the output for the get_menu tool
-type GetMenuOutput struct {
-	Location string   `json:"location"`
-	Date     string   `json:"date"`
-	MealType string   `json:"mealType"`
-	Items    []string `json:"items"`
-	Error    string   `json:"error,omitempty"`
-}
-
-// GetMenu fetches the menu for a specific location, date, and meal type
-func GetMenu(ctx context.Context, req *mcp.CallToolRequest, input GetMenuInput) (
-	*mcp.CallToolResult,
-	GetMenuOutput,
-	error,
-) {
-	if err := initClient(); err != nil {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Failed to initialize client: " + err.Error()},
-			},
-		}, GetMenuOutput{}, nil
-	}
-
-	// Validate location
-	if !config.IsValidLocation(input.Location) {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Invalid location: " + input.Location},
-			},
-		}, GetMenuOutput{}, nil
-	}
-
-	// Validate meal type
-	if !config.IsValidMealType(input.MealType) {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Invalid meal type: " + input.MealType},
-			},
-		}, GetMenuOutput{}, nil
-	}
-
-	// Use provided date or default to today
-	date := input.Date
-	if date == "" {
-		date = utils.FormatDate(time.Now())
-	}
-
-	// Fetch menu
-	items, err := diningClient.GetMenu(input.Location, date, input.MealType)
-	if err != nil {
-		return &mcp.CallToolResult{
-				IsError: true,
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "Error fetching menu: " + err.Error()},
-				},
-			}, GetMenuOutput{
-				Location: input.Location,
-				Date:     date,
-				MealType: input.MealType,
-				Error:    err.Error(),
-			}, nil
+// initProviders creates the Stanford client and registers it along with any
+// providers from the config file. Registration adds their locations to the
+// config lists, so main calls it before the server is built.
+func initProviders() error {
+	if menuProviders != nil {
+		return nil
 	}
-
-	return nil, GetMenuOutput{
-		Location: input.Location,
-		Date:     date,
-		MealType: input.MealType,
-		Items:    items,
-	}, nil
-}
-
-// GetMenusRangeInput defines the input for the get_menus_range tool
-type GetMenusRangeInput struct {
-	Location  string `json:"location" jsonschema:"required,description=The dining hall location name"`
-	MealType  string `json:"mealType" jsonschema:"required,description=The meal type"`
-	Days      int    `json:"days" jsonschema:"description=Number of days to fetch (default: 7, max: 30)"`
-	StartDate string `json:"startDate" jsonschema:"description=Start date in M/D/YYYY format. If not provided, uses today's date"`
-}
-
-// GetMenusRangeOutput defines the output for the get_menus_range tool
-type GetMenusRangeOutput struct {
-	Location string              `json:"location"`
-	MealType string              `json:"mealType"`
-	Menus    map[string][]string `json:"menus"`
-	Error    string              `json:"error,omitempty"`
-}
-
-// GetMenusRange fetches menus for multiple days
-func GetMenusRange(ctx context.Context, req *mcp.CallToolRequest, input GetMenusRangeInput) (
-	*mcp.CallToolResult,
-	GetMenusRangeOutput,
-	error,
-) {
 	if err := initClient(); err != nil {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Failed to initialize client: " + err.Error()},
-			},
-		}, GetMenusRangeOutput{}, nil
-	}
-
-	// Validate location
-	if !config.IsValidLocation(input.Location) {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Invalid location: " + input.Location},
-			},
-		}, GetMenusRangeOutput{}, nil
-	}
-
-	// Validate meal type
-	if !config.IsValidMealType(input.MealType) {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Invalid meal type: " + input.MealType},
-			},
-		}, GetMenusRangeOutput{}, nil
-	}
-
-	// Set default days
-	days := input.Days
-	if days <= 0 {
-		days = 7
-	}
-	if days > 30 {
-		days = 30
+		return err
 	}
 
-	// Determine start date
-	var startTime time.Time
-	if input.StartDate != "" {
-		var err error
-		startTime, err = time.Parse("1/2/2006", input.StartDate)
+	registry := provider.NewRegistry()
+	registry.Register(diningClient)
+	for _, ps := range settings.Providers {
+		p, err := provider.New(ps)
 		if err != nil {
-			return &mcp.CallToolResult{
-				IsError: true,
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "Invalid start date format. Use M/D/YYYY format: " + err.Error()},
-				},
-			}, GetMenusRangeOutput{}, nil
+			return fmt.Errorf("failed to load %s provider: %w", ps.Type, err)
+		}
+		if skipped := registry.Register(p); len(skipped) > 0 {
+			log.Printf("Provider %s: locations already served by another provider: %s", p.Name(), strings.Join(skipped, ", "))
 		}
-	} else {
-		startTime = time.Now()
 	}
+	menuProviders = registry
+	return nil
+}
 
-	// Fetch menus for each day
-	menus := make(map[string][]string)
-	for i := 0; i < days; i++ {
-		date := startTime.AddDate(0, 0, i)
-		dateStr := utils.FormatDate(date)
-
-		items, err := diningClient.GetMenu(input.Location, dateStr, input.MealType)
-		if err != nil {
-			// Always set an empty array, never nil
-			menus[dateStr] = []string{}
-			continue
+// toolEnv is the server state the tools use. setup replaces its settings
+// along with the global ones.
+var toolEnv = &tools.Env{
+	Providers: func() (*provider.Registry, error) {
+		if err := initProviders(); err != nil {
+			return nil, err
 		}
-
-		// Ensure items is never nil
-		if items == nil {
-			items = []string{}
+		return menuProviders, nil
+	},
+	Settings: settings,
+	Watches:  watchStore,
+	Scan: func() {
+		if watchScanner != nil {
+			watchScanner.Trigger()
 		}
-		menus[dateStr] = items
-	}
-
-	return nil, GetMenusRangeOutput{
-		Location: input.Location,
-		MealType: input.MealType,
-		Menus:    menus,
-	}, nil
+	},
+	ParserHealth: currentParserHealth,
+	Metrics:      tools.NewMetrics(),
 }
 
+// serverVersion is the version reported to MCP clients and in the OpenAPI document
+const serverVersion = "1.0.0"
+
 // setupServer creates and configures the MCP server with all tools
 func setupServer() *mcp.Server {
 	server := mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "diningbot",
-			Version: "1.0.0",
+			Version: serverVersion,
+		},
+		&mcp.ServerOptions{
+			SubscribeHandler:   menuSubs.Subscribe,
+			UnsubscribeHandler: menuSubs.Unsubscribe,
+			CompletionHandler:  Complete,
 		},
-		nil,
 	)
+	server.AddReceivingMiddleware(tools.NormalizeArguments)
+	tools.Register(server, toolEnv)
+	addResources(server)
+	addPrompts(server)
 
-	// Add tools with manual schema definitions including enum values
-	getMenuSchema := map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"location": map[string]interface{}{
-				"type":        "string",
-				"description": "The dining hall location name",
-				"enum":        config.ValidLocations,
-			},
-			"date": map[string]interface{}{
-				"type":        "string",
-				"description": "Date in M/D/YYYY format (e.g., 1/15/2025). If not provided, uses today's date",
-			},
-			"mealType": map[string]interface{}{
-				"type":        "string",
-				"description": "The meal type",
-				"enum":        config.ValidMealTypes,
-			},
-		},
-		"required": []string{"location", "mealType"},
+	return server
+}
+
+// startWatchScanner starts the background watchlist scan, delivering alerts
+// via MCP logging plus any configured webhook or SMTP notifiers
+func startWatchScanner(server *mcp.Server) {
+	if err := initProviders(); err != nil {
+		log.Printf("Watchlist disabled: failed to initialize providers: %v", err)
+		return
 	}
 
-	getMenusRangeSchema := map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"location": map[string]interface{}{
-				"type":        "string",
-				"description": "The dining hall location name",
-				"enum":        config.ValidLocations,
-			},
-			"mealType": map[string]interface{}{
-				"type":        "string",
-				"description": "The meal type",
-				"enum":        config.ValidMealTypes,
-			},
-			"days": map[string]interface{}{
-				"type":        "integer",
-				"description": "Number of days to fetch (default: 7, max: 30)",
-			},
-			"startDate": map[string]interface{}{
-				"type":        "string",
-				"description": "Start date in M/D/YYYY format. If not provided, uses today's date",
-			},
-		},
-		"required": []string{"location", "mealType"},
+	notifiers := []watchlist.Notifier{&watchlist.MCPLogNotifier{Server: server}}
+	watch := settings.Watch
+	if watch.WebhookURL != "" {
+		notifiers = append(notifiers, watchlist.NewWebhookNotifier(watch.WebhookURL))
+	}
+	if watch.SMTP.Addr != "" {
+		smtpNotifier := &watchlist.SMTPNotifier{
+			Addr: watch.SMTP.Addr,
+			From: watch.SMTP.From,
+			To:   watch.SMTP.To,
+		}
+		if watch.SMTP.User != "" {
+			host, _, _ := net.SplitHostPort(watch.SMTP.Addr)
+			smtpNotifier.Auth = smtp.PlainAuth("", watch.SMTP.User, watch.SMTP.Password, host)
+		}
+		notifiers = append(notifiers, smtpNotifier)
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_menu",
-		Description: "Get the menu for a specific dining hall location, date, and meal type",
-		InputSchema: getMenuSchema,
-	}, GetMenu)
+	watchScanner = watchlist.NewScanner(watchStore, menuProviders, notifiers...)
+	go watchScanner.Run(context.Background(), time.Duration(watch.Interval))
+}
 
-	// Define output schema for get_menus_range to handle empty arrays
-	getMenusRangeOutputSchema := map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"location": map[string]interface{}{
-				"type": "string",
-			},
-			"mealType": map[string]interface{}{
-				"type": "string",
-			},
-			"menus": map[string]interface{}{
-				"type": "object",
-				"additionalProperties": map[string]interface{}{
-					"type":  "array",
-					"items": map[string]interface{}{"type": "string"},
-				},
-			},
-		},
+// discoverSiteOptions merges the halls and meal types listed on the menu site into
+// the config, so the tool schema enums built afterwards include newly opened halls.
+// If the site can't be reached within the discovery timeout the configured lists
+// are used.
+func discoverSiteOptions() {
+	if !settings.Site.Discover {
+		return
+	}
+	if err := initClient(); err != nil {
+		log.Printf("Site discovery skipped: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(settings.Site.DiscoverTimeout))
+	defer cancel()
+	options, err := diningClient.Options(ctx)
+	if err != nil {
+		log.Printf("Site discovery failed, using built-in locations and meal types: %v", err)
+		return
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:         "get_menus_range",
-		Description:  "Get menus for multiple days for a specific dining hall location and meal type",
-		InputSchema:  getMenusRangeSchema,
-		OutputSchema: getMenusRangeOutputSchema,
-	}, GetMenusRange)
+	locations := make([]config.Location, len(options.Locations))
+	for i, o := range options.Locations {
+		locations[i] = config.Location{Name: o.Text, Value: o.Value}
+	}
+	mealTypes := make([]string, len(options.MealTypes))
+	for i, o := range options.MealTypes {
+		mealTypes[i] = o.Value
+	}
+	if added := config.MergeDiscovered(locations, mealTypes); len(added) > 0 {
+		log.Printf("Discovered on the menu site: %s", strings.Join(added, ", "))
+	}
+}
 
-	return server
+// startMenuRefresh periodically refreshes subscribed menu resources so
+// subscribers are notified when a menu changes during the day
+func startMenuRefresh(server *mcp.Server) {
+	go menuSubs.Run(context.Background(), server, time.Duration(settings.Server.MenuRefreshInterval))
 }
 
 func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// setup loads settings and registers the menu providers; every command runs it
+// first. Only commands that build tool schemas need discover, which merges the
+// site's halls and meal types into the config.
+func setup(configPath string, discover bool) error {
+	loaded, err := config.LoadSettings(configPath)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	settings = loaded
+	settings.Apply()
+	toolEnv.Settings = settings
+
+	// Campus timezone decides what "today" means, regardless of the host's timezone
+	if err := dates.SetCampus(settings.Server.CampusTimezone); err != nil {
+		return err
+	}
+
+	// Discovery and provider registration must finish before setupServer builds the schema enums
+	if discover {
+		discoverSiteOptions()
+	}
+	return initProviders()
+}
+
+// serve runs the MCP server until it fails, returning the exit code
+func serve() int {
 	server := setupServer()
+	startWatchScanner(server)
+	startMenuRefresh(server)
 
-	// Check if PORT is set - if so, run as remote Streamable HTTP server, otherwise use stdio
-	port := os.Getenv("PORT")
+	// Check if a port is set - if so, run as remote Streamable HTTP server, otherwise use stdio
+	port := settings.Server.Port
 	if port != "" {
 		// Remote mode: Run Streamable HTTP server (MCP 2025-06-18)
 		// Per spec: bind to localhost by default for security, but allow override for Docker
-		bindAddr := os.Getenv("BIND_ADDR")
-		if bindAddr == "" {
-			bindAddr = "127.0.0.1" // Default to localhost per security recommendations
-		}
+		bindAddr := settings.Server.BindAddr
 
 		handler := mcp.NewStreamableHTTPHandler(
 			func(request *http.Request) *mcp.Server {
@@ -330,20 +256,31 @@ func main() {
 		// Set up HTTP routes - single MCP endpoint per spec
 		// The handler supports both POST (client requests) and GET (server-initiated streams)
 		http.Handle("/mcp", handler)
+		http.HandleFunc("/health", handleHealth)
+		http.HandleFunc("/ical/", handleICal)
+		http.HandleFunc("/feeds/", handleFeed)
+		http.Handle("/api/", newAPIHandler())
 		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "text/plain")
 			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("DiningBot MCP Server\n\nConnect to /mcp for Streamable HTTP transport (MCP 2025-06-18)\n"))
+			w.Write([]byte("DiningBot MCP Server\n\nConnect to /mcp for Streamable HTTP transport (MCP 2025-06-18)\n" +
+				"Subscribe to /ical/{location}.ics for a calendar of meals\n" +
+				"Follow /feeds/{location}.atom or /feeds/{location}.rss in a feed reader\n" +
+				"Fetch JSON from the REST API at /api/v1/locations and /api/v1/menus/{location}?meal=\n" +
+				"Read the REST API's OpenAPI document at /api/openapi.json\n"))
 		})
 
 		log.Printf("MCP server listening on %s", addr)
 		log.Printf("Streamable HTTP endpoint: http://%s/mcp", addr)
 		log.Printf("Protocol: MCP 2025-06-18 (Streamable HTTP)")
-		log.Fatal(http.ListenAndServe(addr, nil))
-	} else {
-		// Local mode: Run over stdin/stdout
-		if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-			log.Fatal(err)
-		}
+		log.Print(http.ListenAndServe(addr, nil))
+		return exitError
+	}
+
+	// Local mode: Run over stdin/stdout
+	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		log.Print(err)
+		return exitError
 	}
+	return exitOK
 }
@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/tools"
 	"github.com/bklieger/diningbot/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -70,7 +73,7 @@ func TestMCPListTools(t *testing.T) {
 		t.Fatalf("Failed to list tools: %v", err)
 	}
 
-	expectedTools := []string{"get_menu", "get_menus_range"}
+	expectedTools := []string{"get_menu", "get_menus_range", "list_available_dates", "get_item_details", "parser_health"}
 	toolNames := make(map[string]bool)
 	for _, tool := range result.Tools {
 		toolNames[tool.Name] = true
@@ -127,7 +130,7 @@ func TestMCPGetMenu(t *testing.T) {
 		// Try to extract structured output
 		if result.StructuredContent != nil {
 			outputJSON, _ := json.Marshal(result.StructuredContent)
-			var output GetMenuOutput
+			var output tools.GetMenuOutput
 			if err := json.Unmarshal(outputJSON, &output); err == nil {
 				t.Logf("Menu retrieved: %d items for %s on %s", len(output.Items), output.MealType, output.Date)
 			}
@@ -257,7 +260,7 @@ func TestMCPGetMenusRange(t *testing.T) {
 	// Extract structured output
 	if result.StructuredContent != nil {
 		outputJSON, _ := json.Marshal(result.StructuredContent)
-		var output GetMenusRangeOutput
+		var output tools.GetMenusRangeOutput
 		if err := json.Unmarshal(outputJSON, &output); err != nil {
 			t.Fatalf("Failed to unmarshal menus range: %v", err)
 		}
@@ -296,25 +299,486 @@ func TestMCPInvalidInput(t *testing.T) {
 	}
 	defer session.Close()
 
-	// Test invalid location - enum validation happens at protocol level
+	// Unknown locations are rejected with a tool error suggesting close matches
 	params := &mcp.CallToolParams{
 		Name: "get_menu",
 		Arguments: map[string]interface{}{
-			"location": "Invalid Location",
+			"location": "Arrilaga",
 			"mealType": "Lunch",
 		},
 	}
 
 	result, err := session.CallTool(ctx, params)
-	// Enum validation happens before the tool is called, so we expect a protocol error
-	if err == nil {
-		t.Error("Expected error for invalid location, but call succeeded")
-		// If it somehow succeeded, check the result
-		if result != nil && !result.IsError {
-			t.Error("Expected error for invalid location, but got success")
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected error for invalid location, but got success")
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "did you mean") || !strings.Contains(text, "Arrillaga Family Dining Commons") {
+		t.Errorf("Expected a suggestion in the error, got %q", text)
+	}
+
+	// Unrelated values get no suggestions
+	params.Arguments = map[string]interface{}{
+		"location": "Invalid Location",
+		"mealType": "Lunch",
+	}
+	result, err = session.CallTool(ctx, params)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error for invalid location, but got success")
+	}
+}
+
+// TestMCPLocationAliases tests that nicknames are resolved before schema validation
+func TestMCPLocationAliases(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "watch_dish",
+		Arguments: map[string]interface{}{
+			"dish":      "pho",
+			"locations": []string{"FloMo", "casper"},
+			"mealTypes": []string{"supper"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("watch_dish returned error: %v", result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"Florence Moore Dining", "Gerhard Casper Dining", "Dinner"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in watch, got %s", want, text)
+		}
+	}
+
+	// Nicknames pass the get_menu enum; the result is keyed by canonical names
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "get_menu",
+		Arguments: map[string]interface{}{
+			"location": "lakeside",
+			"mealType": "lunch",
+		},
+	})
+	if err != nil {
+		t.Fatalf("get_menu with nicknames failed at the protocol level: %v", err)
+	}
+	if out, ok := result.StructuredContent.(map[string]interface{}); ok {
+		if out["location"] != "Lakeside Dining" || out["mealType"] != "Lunch" {
+			t.Errorf("Expected canonical names in output, got %v", out)
+		}
+	}
+}
+
+// TestMCPParserHealth tests the parser_health tool before any menu is fetched
+func TestMCPParserHealth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "parser_health",
+		Arguments: map[string]interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("parser_health returned error: %v", result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, `"status":"unknown"`) || !strings.Contains(text, `"pagesChecked":0`) {
+		t.Errorf("Expected unknown parser health before any menu is fetched, got %s", text)
+	}
+}
+
+// TestMCPGetItemDetailsInvalidDate tests that get_item_details reports a bad date before fetching
+func TestMCPGetItemDetailsInvalidDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "get_item_details",
+		Arguments: map[string]interface{}{
+			"location": "Branner Dining",
+			"item":     "Oatmeal",
+			"date":     "not a date",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected an error for an invalid date")
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "Invalid date") {
+		t.Errorf("Expected an invalid date error, got %s", text)
+	}
+}
+
+// TestMCPMenuFormat tests that format aliases are accepted and unknown formats are rejected before fetching
+func TestMCPMenuFormat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "get_menus_range",
+		Arguments: map[string]interface{}{
+			"location": "Branner Dining",
+			"mealType": "Lunch",
+			"format":   "yaml",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected an error for an unknown format")
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "unknown format") || !strings.Contains(text, "markdown") {
+		t.Errorf("Expected the valid formats in the error, got %s", text)
+	}
+
+	// "MD" passes the schema enum as "markdown"; an unreachable site still fails, but not on validation
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "get_menu",
+		Arguments: map[string]interface{}{
+			"location": "Branner Dining",
+			"mealType": "Lunch",
+			"format":   "MD",
+		},
+	})
+	if err != nil {
+		t.Fatalf("get_menu with a format alias failed at the protocol level: %v", err)
+	}
+	if !result.IsError {
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.HasPrefix(text, "## Branner Dining, Lunch, ") {
+			t.Errorf("Expected a Markdown menu, got %s", text)
+		}
+	}
+}
+
+// TestMCPResources tests listing resources and reading the locations resource
+func TestMCPResources(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	templates, err := session.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{})
+	if err != nil {
+		t.Fatalf("Failed to list resource templates: %v", err)
+	}
+	if len(templates.ResourceTemplates) == 0 || templates.ResourceTemplates[0].URITemplate != menuURITemplate {
+		t.Errorf("Expected resource template %s, got %v", menuURITemplate, templates.ResourceTemplates)
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: locationsURI})
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", locationsURI, err)
+	}
+
+	var locations LocationsResource
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &locations); err != nil {
+		t.Fatalf("Failed to unmarshal locations: %v", err)
+	}
+	if len(locations.Locations) != len(config.ValidLocations) {
+		t.Errorf("Expected %d locations, got %d", len(config.ValidLocations), len(locations.Locations))
+	}
+
+	// Reading a menu goes through the same client and cache as get_menu
+	uri := menuURI("Branner Dining", dates.Today(), "Lunch")
+	menu, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", uri, err)
+	}
+	var output tools.GetMenuOutput
+	if err := json.Unmarshal([]byte(menu.Contents[0].Text), &output); err != nil {
+		t.Fatalf("Failed to unmarshal menu: %v", err)
+	}
+	t.Logf("Menu resource returned %d items", len(output.Items))
+
+	// Unknown locations are reported as not found
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: menuURI("Nowhere", "today", "Lunch")}); err == nil {
+		t.Error("Expected error reading menu for invalid location")
+	}
+}
+
+// TestMCPResourceSubscriptions tests subscribing to and unsubscribing from menu resources
+func TestMCPResourceSubscriptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	if caps := session.InitializeResult().Capabilities; caps.Resources == nil || !caps.Resources.Subscribe {
+		t.Fatal("Server does not advertise resource subscriptions")
+	}
+
+	uri := menuURI("Branner Dining", "today", "Lunch")
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Failed to subscribe to %s: %v", uri, err)
+	}
+	if err := session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Failed to unsubscribe from %s: %v", uri, err)
+	}
+
+	// Subscribing to a menu that can't exist is rejected
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: menuURI("Nowhere", "today", "Lunch")}); err == nil {
+		t.Error("Expected error subscribing to invalid location")
+	}
+}
+
+// TestMCPPrompts tests listing prompts and rendering one with live menu data
+func TestMCPPrompts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.ListPrompts(ctx, &mcp.ListPromptsParams{})
+	if err != nil {
+		t.Fatalf("Failed to list prompts: %v", err)
+	}
+
+	promptNames := make(map[string]bool)
+	for _, prompt := range result.Prompts {
+		promptNames[prompt.Name] = true
+	}
+	for _, expected := range []string{"plan_my_day", "allergy_safe_dinner", "weekly_meal_plan"} {
+		if !promptNames[expected] {
+			t.Errorf("Expected prompt %s not found", expected)
+		}
+	}
+
+	prompt, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name: "allergy_safe_dinner",
+		Arguments: map[string]string{
+			"allergies": "peanuts",
+			"location":  "Branner Dining",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to get allergy_safe_dinner: %v", err)
+	}
+	if len(prompt.Messages) == 0 {
+		t.Fatal("allergy_safe_dinner returned no messages")
+	}
+	if tc, ok := prompt.Messages[0].Content.(*mcp.TextContent); !ok || !strings.Contains(tc.Text, "Branner Dining") {
+		t.Errorf("Prompt does not embed the Branner Dining menu: %v", prompt.Messages[0].Content)
+	}
+
+	// Invalid locations are rejected
+	if _, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "weekly_meal_plan",
+		Arguments: map[string]string{"location": "Nowhere"},
+	}); err == nil {
+		t.Error("Expected error for invalid location")
+	}
+}
+
+// TestMCPCompletion tests argument completion for prompts and resource templates
+func TestMCPCompletion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	binaryPath := buildBinary(t)
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(binaryPath)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.Complete(ctx, &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "weekly_meal_plan"},
+		Argument: mcp.CompleteParamsArgument{Name: "location", Value: "arri"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to complete location: %v", err)
+	}
+	if len(result.Completion.Values) == 0 || result.Completion.Values[0] != "Arrillaga Family Dining Commons" {
+		t.Errorf("Expected Arrillaga Family Dining Commons first, got %v", result.Completion.Values)
+	}
+
+	result, err = session.Complete(ctx, &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/resource", URI: menuURITemplate},
+		Argument: mcp.CompleteParamsArgument{Name: "date", Value: ""},
+	})
+	if err != nil {
+		t.Fatalf("Failed to complete date: %v", err)
+	}
+	if len(result.Completion.Values) != completionDays+1 || result.Completion.Values[1] != dates.Today() {
+		t.Errorf("Expected today plus %d dates, got %v", completionDays, result.Completion.Values)
+	}
+}
+
+// TestMCPDerivedSchemas tests that every tool's schemas are derived from its
+// handler's types, with required arguments and enums from config
+func TestMCPDerivedSchemas(t *testing.T) {
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := setupServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	wantRequired := map[string][]string{
+		"get_menu":             {"location", "mealType"},
+		"get_menus_range":      {"location", "mealType"},
+		"list_available_dates": {"location"},
+		"get_item_details":     {"location", "item"},
+		"plan_meals":           {"locations"},
+	}
+	wantEnums := map[string][]string{
+		"location":  config.ValidLocations,
+		"locations": config.ValidLocations,
+		"mealType":  config.ValidMealTypes,
+		"mealTypes": config.ValidMealTypes,
+	}
+	for _, tool := range tools.Tools {
+		if tool.OutputSchema == nil {
+			t.Errorf("%s: missing output schema", tool.Name)
+		}
+		schemaJSON, _ := json.Marshal(tool.InputSchema)
+		var schema struct {
+			Required   []string `json:"required"`
+			Properties map[string]struct {
+				Enum  []string `json:"enum"`
+				Items struct {
+					Enum []string `json:"enum"`
+				} `json:"items"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+			t.Fatalf("%s: invalid input schema %s: %v", tool.Name, schemaJSON, err)
+		}
+		if want, ok := wantRequired[tool.Name]; ok && strings.Join(schema.Required, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: required = %v, want %v", tool.Name, schema.Required, want)
+		}
+		for name, want := range wantEnums {
+			property, ok := schema.Properties[name]
+			if !ok {
+				continue
+			}
+			got := property.Enum
+			if got == nil {
+				got = property.Items.Enum
+			}
+			if strings.Join(got, "|") != strings.Join(want, "|") {
+				t.Errorf("%s: %s enum = %v, want %v", tool.Name, name, got, want)
+			}
 		}
-	} else {
-		// This is expected - enum validation catches invalid values
-		t.Logf("Got expected validation error for invalid location: %v", err)
 	}
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/tools"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// openAPIPath is where the REST API's OpenAPI document is served
+const openAPIPath = "/api/openapi.json"
+
+// apiOpenAPI serves the OpenAPI document describing the REST API
+func apiOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIResponse(w, r, "openapi", doc)
+}
+
+// openAPIDocument builds the OpenAPI 3.1 document of the REST API. Response
+// schemas are derived from the Go types the handlers encode, and location
+// and meal type enums come from config, so the document follows both as
+// they change, including locations discovered on the menu site.
+func openAPIDocument() (map[string]any, error) {
+	schemas := map[string]any{}
+	for name, schemaFor := range map[string]func() (*jsonschema.Schema, error){
+		"LocationsResource":   schemaOf[LocationsResource],
+		"GetMenuOutput":       schemaOf[tools.GetMenuOutput],
+		"GetMenusRangeOutput": schemaOf[tools.GetMenusRangeOutput],
+		"APIError":            schemaOf[APIError],
+	} {
+		schema, err := schemaFor()
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = schema
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "DiningBot REST API",
+			"version":     serverVersion,
+			"description": "Dining hall menus as plain JSON. Locations and meal types also accept nicknames, and dates accept relative expressions like \"tomorrow\".",
+		},
+		"paths": map[string]any{
+			apiPrefix + "/locations": map[string]any{
+				"get": map[string]any{
+					"operationId": "listLocations",
+					"summary":     "List the dining hall locations and meal types",
+					"responses": map[string]any{
+						"200": okResponse("The locations and meal types", "LocationsResource"),
+						"304": map[string]any{"$ref": "#/components/responses/NotModified"},
+					},
+				},
+			},
+			apiPrefix + "/menus/{location}": map[string]any{
+				"parameters": []any{map[string]any{"$ref": "#/components/parameters/location"}},
+				"get": map[string]any{
+					"operationId": "getMenu",
+					"summary":     "Get the menu for a location, date and meal type",
+					"parameters": []any{
+						map[string]any{"$ref": "#/components/parameters/meal"},
+						map[string]any{
+							"name":        "date",
+							"in":          "query",
+							"description": "Date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'tomorrow'. If not provided, uses today's date",
+							"schema":      map[string]any{"type": "string"},
+						},
+					},
+					"responses": menuResponses("The menu", "GetMenuOutput"),
+				},
+			},
+			apiPrefix + "/menus/{location}/range": map[string]any{
+				"parameters": []any{map[string]any{"$ref": "#/components/parameters/location"}},
+				"get": map[string]any{
+					"operationId": "getMenusRange",
+					"summary":     "Get a location's menus for a meal type over several days",
+					"parameters": []any{
+						map[string]any{"$ref": "#/components/parameters/meal"},
+						map[string]any{
+							"name":        "start",
+							"in":          "query",
+							"description": "Start date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'this weekend'. If not provided, uses today's date",
+							"schema":      map[string]any{"type": "string"},
+						},
+						map[string]any{
+							"name":        "days",
+							"in":          "query",
+							"description": "Number of days to fetch, limited to the configured maximum. If not provided, uses the configured default",
+							"schema":      map[string]any{"type": "integer", "minimum": 1},
+						},
+					},
+					"responses": menuResponses("The menus by date", "GetMenusRangeOutput"),
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": schemas,
+			"parameters": map[string]any{
+				"location": map[string]any{
+					"name":        "location",
+					"in":          "path",
+					"required":    true,
+					"description": "The dining hall location name",
+					"schema":      map[string]any{"type": "string", "enum": config.ValidLocations},
+				},
+				"meal": map[string]any{
+					"name":        "meal",
+					"in":          "query",
+					"required":    true,
+					"description": "The meal type",
+					"schema":      map[string]any{"type": "string", "enum": config.ValidMealTypes},
+				},
+			},
+			"headers": map[string]any{
+				"ETag": map[string]any{
+					"description": "Hash of the response body, for If-None-Match",
+					"schema":      map[string]any{"type": "string"},
+				},
+				"Last-Modified": map[string]any{
+					"description": "When the response last changed, for If-Modified-Since",
+					"schema":      map[string]any{"type": "string"},
+				},
+			},
+			"responses": map[string]any{
+				"NotModified": map[string]any{
+					"description": "The response hasn't changed since the ETag or time given in If-None-Match or If-Modified-Since",
+				},
+			},
+		},
+	}, nil
+}
+
+// schemaOf derives the JSON schema of the JSON encoding of T
+func schemaOf[T any]() (*jsonschema.Schema, error) {
+	return jsonschema.For[T](nil)
+}
+
+// okResponse describes a successful JSON response with caching headers
+func okResponse(description, schema string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"headers": map[string]any{
+			"ETag":          map[string]any{"$ref": "#/components/headers/ETag"},
+			"Last-Modified": map[string]any{"$ref": "#/components/headers/Last-Modified"},
+		},
+		"content": jsonContent(schema),
+	}
+}
+
+// menuResponses describes the responses of the menu endpoints
+func menuResponses(description, schema string) map[string]any {
+	return map[string]any{
+		"200": okResponse(description, schema),
+		"304": map[string]any{"$ref": "#/components/responses/NotModified"},
+		"400": errorResponse("The meal is missing or unknown, or another parameter is invalid"),
+		"404": errorResponse("The location is unknown"),
+		"502": errorResponse("The menu couldn't be fetched from the menu site; for a range, no day's menu could be"),
+		"503": errorResponse("The menu providers couldn't be initialized"),
+	}
+}
+
+// errorResponse describes an error response
+func errorResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     jsonContent("APIError"),
+	}
+}
+
+// jsonContent is the content of a JSON response with a component schema
+func jsonContent(schema string) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": map[string]any{"$ref": "#/components/schemas/" + schema},
+		},
+	}
+}
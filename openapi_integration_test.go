@@ -0,0 +1,135 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// openAPISpec fetches the OpenAPI document from the REST API
+func openAPISpec(t *testing.T) map[string]any {
+	t.Helper()
+	rec := apiGet(t, http.MethodGet, openAPIPath, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body, err)
+	}
+	if doc["openapi"] != "3.1.0" {
+		t.Fatalf("openapi = %v, want 3.1.0", doc["openapi"])
+	}
+	return doc
+}
+
+// lookup follows a path of object keys through a JSON document, resolving
+// local $refs along the way
+func lookup(t *testing.T, doc map[string]any, keys ...string) map[string]any {
+	t.Helper()
+	node := doc
+	for _, key := range keys {
+		next, ok := node[key].(map[string]any)
+		if !ok {
+			t.Fatalf("no %q in OpenAPI document at %s", key, strings.Join(keys, "/"))
+		}
+		if ref, ok := next["$ref"].(string); ok {
+			next = lookup(t, doc, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...)
+		}
+		node = next
+	}
+	return node
+}
+
+// TestOpenAPIEnums tests that the document lists the configured locations and meal types
+func TestOpenAPIEnums(t *testing.T) {
+	doc := openAPISpec(t)
+	for name, want := range map[string][]string{
+		"location": config.ValidLocations,
+		"meal":     config.ValidMealTypes,
+	} {
+		schema := lookup(t, doc, "components", "parameters", name, "schema")
+		data, _ := json.Marshal(schema["enum"])
+		var got []string
+		json.Unmarshal(data, &got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s enum = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestOpenAPIContract validates real REST API responses against the schemas
+// the OpenAPI document gives for their path and status
+func TestOpenAPIContract(t *testing.T) {
+	useStaticMenus(t, dates.Today(), []string{"Pho", "Rice"})
+	doc := openAPISpec(t)
+
+	tests := []struct {
+		path, url string
+		code      int
+	}{
+		{"/api/v1/locations", "/api/v1/locations", http.StatusOK},
+		{"/api/v1/menus/{location}", "/api/v1/menus/branner?meal=Lunch", http.StatusOK},
+		{"/api/v1/menus/{location}", "/api/v1/menus/branner?meal=Lunch&date=today", http.StatusOK},
+		{"/api/v1/menus/{location}", "/api/v1/menus/nowhere?meal=Lunch", http.StatusNotFound},
+		{"/api/v1/menus/{location}", "/api/v1/menus/branner", http.StatusBadRequest},
+		{"/api/v1/menus/{location}/range", "/api/v1/menus/branner/range?meal=Lunch&days=2", http.StatusOK},
+		{"/api/v1/menus/{location}/range", "/api/v1/menus/branner/range?meal=Lunch&days=0", http.StatusBadRequest},
+	}
+	tested := map[string]bool{}
+	for _, tt := range tests {
+		tested[tt.path] = true
+		rec := apiGet(t, http.MethodGet, tt.url, nil)
+		if rec.Code != tt.code {
+			t.Errorf("GET %s = %d, want %d: %s", tt.url, rec.Code, tt.code, rec.Body)
+			continue
+		}
+
+		responsePath := []string{"paths", tt.path, "get", "responses", strconv.Itoa(tt.code)}
+		response := lookup(t, doc, responsePath...)
+		if tt.code == http.StatusOK {
+			for header := range response["headers"].(map[string]any) {
+				if rec.Header().Get(header) == "" {
+					t.Errorf("GET %s: missing documented header %s", tt.url, header)
+				}
+			}
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("GET %s: Content-Type = %q", tt.url, got)
+		}
+		schemaJSON, _ := json.Marshal(lookup(t, doc, append(responsePath, "content", "application/json", "schema")...))
+		var schema jsonschema.Schema
+		if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+			t.Fatalf("%s %d: invalid schema: %v", tt.path, tt.code, err)
+		}
+		resolved, err := schema.Resolve(nil)
+		if err != nil {
+			t.Fatalf("%s %d: resolving schema: %v", tt.path, tt.code, err)
+		}
+
+		var body any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("GET %s: invalid JSON %q: %v", tt.url, rec.Body, err)
+			continue
+		}
+		if err := resolved.Validate(body); err != nil {
+			t.Errorf("GET %s: response %s doesn't match the OpenAPI schema: %v", tt.url, rec.Body, err)
+		}
+	}
+
+	for path := range lookup(t, doc, "paths") {
+		if !tested[path] {
+			t.Errorf("documented path %s has no contract test", path)
+		}
+	}
+}
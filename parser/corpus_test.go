@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRegressionCorpus runs the default rules over saved menu pages and checks
+// the items against each page's .golden file, one item per line. A mismatch
+// is either a leak (navigation or footer text read as a dish) or a dropped
+// dish. The pages are modeled on the menu site's markup, including older
+// layouts without the menu container; save real pages here with their expected
+// items when the markup changes.
+func TestRegressionCorpus(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.html"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no pages in testdata/corpus")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			html, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			golden, err := os.ReadFile(strings.TrimSuffix(page, ".html") + ".golden")
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			want := []string{}
+			for _, line := range strings.Split(string(golden), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					want = append(want, line)
+				}
+			}
+
+			got := []string{}
+			for _, item := range DefaultRuleSet().ExtractItems(string(html), false) {
+				got = append(got, item.Name)
+			}
+			for _, item := range got {
+				if !slices.Contains(want, item) {
+					t.Errorf("leaked %q", item)
+				}
+			}
+			for _, item := range want {
+				if !slices.Contains(got, item) {
+					t.Errorf("dropped %q", item)
+				}
+			}
+			if !t.Failed() && !slices.Equal(got, want) {
+				t.Errorf("items out of order: got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Structure summarizes a page's markup, to tell page layouts apart. The hash
+// covers which tags, ids and classes appear but not how often, so pages with
+// the same layout and different menus hash alike.
+type Structure struct {
+	Hash     string         `json:"hash"`
+	Elements int            `json:"elements"`
+	Tags     map[string]int `json:"tags"`
+	IDs      []string       `json:"ids"`
+	Classes  []string       `json:"classes"`
+}
+
+// Fingerprint returns the structure of an HTML page
+func Fingerprint(htmlContent string) Structure {
+	s := Structure{Tags: make(map[string]int), IDs: []string{}, Classes: []string{}}
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return s
+	}
+
+	ids := make(map[string]bool)
+	classes := make(map[string]bool)
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			s.Elements++
+			s.Tags[n.Data]++
+			if id := getAttr(n, "id"); id != "" {
+				ids[id] = true
+			}
+			for _, class := range strings.Fields(getAttr(n, "class")) {
+				classes[class] = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	s.IDs = append(s.IDs, slices.Sorted(maps.Keys(ids))...)
+	s.Classes = append(s.Classes, slices.Sorted(maps.Keys(classes))...)
+
+	h := sha256.New()
+	for _, part := range [][]string{slices.Sorted(maps.Keys(s.Tags)), s.IDs, s.Classes} {
+		fmt.Fprintln(h, strings.Join(part, " "))
+	}
+	s.Hash = hex.EncodeToString(h.Sum(nil))[:12]
+	return s
+}
+
+// String formats the structure for logs
+func (s Structure) String() string {
+	tags := make([]string, 0, len(s.Tags))
+	for _, tag := range slices.Sorted(maps.Keys(s.Tags)) {
+		tags = append(tags, fmt.Sprintf("%s:%d", tag, s.Tags[tag]))
+	}
+	return fmt.Sprintf("hash=%s elements=%d tags=[%s] ids=[%s] classes=[%s]",
+		s.Hash, s.Elements, strings.Join(tags, " "), strings.Join(s.IDs, " "), strings.Join(s.Classes, " "))
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	lunch := `<div id="MainContent_divMenu"><ul><li class="clsMenuItem">Soup</li><li class="clsMenuItem">Salad</li></ul></div>`
+	dinner := `<div id="MainContent_divMenu"><ul><li class="clsMenuItem">Pasta</li></ul></div>`
+	redesign := `<main class="menu"><article class="dish">Pasta</article></main>`
+
+	s := Fingerprint(lunch)
+	if s.Tags["li"] != 2 || !reflect.DeepEqual(s.IDs, []string{"MainContent_divMenu"}) || !reflect.DeepEqual(s.Classes, []string{"clsMenuItem"}) {
+		t.Errorf("Fingerprint() = %+v", s)
+	}
+	if len(s.Hash) != 12 {
+		t.Errorf("Hash = %q, want 12 hex digits", s.Hash)
+	}
+	if Fingerprint(dinner).Hash != s.Hash {
+		t.Error("pages with the same layout should hash alike")
+	}
+	if Fingerprint(redesign).Hash == s.Hash {
+		t.Error("pages with different layouts should hash differently")
+	}
+
+	str := s.String()
+	for _, want := range []string{"hash=" + s.Hash, "li:2", "ids=[MainContent_divMenu]", "classes=[clsMenuItem]"} {
+		if !strings.Contains(str, want) {
+			t.Errorf("String() = %q, want it to contain %q", str, want)
+		}
+	}
+
+	empty := Fingerprint("")
+	if empty.IDs == nil || empty.Classes == nil {
+		t.Error("Fingerprint() should return empty lists, not nil")
+	}
+}
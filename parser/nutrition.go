@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Nutrition is an item's nutrition label. Masses are in grams except
+// cholesterol and sodium, which are in milligrams; nil means not listed.
+type Nutrition struct {
+	ServingSize   string   `json:"servingSize,omitempty"`
+	Calories      *float64 `json:"calories,omitempty"`
+	TotalFat      *float64 `json:"totalFat,omitempty"`
+	SaturatedFat  *float64 `json:"saturatedFat,omitempty"`
+	TransFat      *float64 `json:"transFat,omitempty"`
+	Cholesterol   *float64 `json:"cholesterol,omitempty"`
+	Sodium        *float64 `json:"sodium,omitempty"`
+	Carbohydrates *float64 `json:"carbohydrates,omitempty"`
+	Fiber         *float64 `json:"fiber,omitempty"`
+	Sugars        *float64 `json:"sugars,omitempty"`
+	Protein       *float64 `json:"protein,omitempty"`
+}
+
+// nutrient reads one value from a label line. The first match on the page
+// wins, so "Total Sugars" is read before an "Added Sugars" line below it.
+type nutrient struct {
+	pattern *regexp.Regexp
+	// unit is the unit stored in Nutrition: "g", "mg" or "" for calories
+	unit  string
+	field func(*Nutrition) **float64
+}
+
+// number matches a label value with an optional unit, like "10g" or "<1 g"
+const number = `\s*:?\s*<?\s*(\d+(?:\.\d+)?)\s*(mg|g)?\b`
+
+var nutrients = []nutrient{
+	{regexp.MustCompile(`(?i)\bcalories` + number), "", func(n *Nutrition) **float64 { return &n.Calories }},
+	{regexp.MustCompile(`(?i)\btotal fat` + number), "g", func(n *Nutrition) **float64 { return &n.TotalFat }},
+	{regexp.MustCompile(`(?i)\bsaturated fat` + number), "g", func(n *Nutrition) **float64 { return &n.SaturatedFat }},
+	{regexp.MustCompile(`(?i)\btrans fat` + number), "g", func(n *Nutrition) **float64 { return &n.TransFat }},
+	{regexp.MustCompile(`(?i)\bcholesterol` + number), "mg", func(n *Nutrition) **float64 { return &n.Cholesterol }},
+	{regexp.MustCompile(`(?i)\bsodium` + number), "mg", func(n *Nutrition) **float64 { return &n.Sodium }},
+	{regexp.MustCompile(`(?i)\b(?:total )?carbohydrates?` + number), "g", func(n *Nutrition) **float64 { return &n.Carbohydrates }},
+	{regexp.MustCompile(`(?i)\b(?:dietary )?fiber` + number), "g", func(n *Nutrition) **float64 { return &n.Fiber }},
+	{regexp.MustCompile(`(?i)\b(?:total )?sugars` + number), "g", func(n *Nutrition) **float64 { return &n.Sugars }},
+	{regexp.MustCompile(`(?i)\bprotein` + number), "g", func(n *Nutrition) **float64 { return &n.Protein }},
+}
+
+var (
+	servingSizePattern = regexp.MustCompile(`(?i)\bserving size\s*:?\s*(.+)`)
+	// servingSizeEnd cuts a serving size at the next label on the same line
+	servingSizeEnd = regexp.MustCompile(`(?i)\s*\b(servings per|amount per|calories)\b`)
+)
+
+// blockElements start a new line of label text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "br": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "li": true, "ol": true, "p": true, "section": true, "table": true,
+	"tbody": true, "thead": true, "tr": true, "ul": true,
+}
+
+// ParseNutrition reads a nutrition label page, reporting false if the page
+// lists neither a serving size nor any nutrient. Labels are read line by
+// line, so "Total Fat 10g" may be one text node or spread over table cells.
+func ParseNutrition(htmlContent string) (Nutrition, bool) {
+	var n Nutrition
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return n, false
+	}
+
+	found := false
+	for _, line := range textLines(doc) {
+		if n.ServingSize == "" {
+			if m := servingSizePattern.FindStringSubmatch(line); m != nil {
+				size := m[1]
+				if loc := servingSizeEnd.FindStringIndex(size); loc != nil {
+					size = size[:loc[0]]
+				}
+				if n.ServingSize = strings.TrimSpace(size); n.ServingSize != "" {
+					found = true
+				}
+			}
+		}
+		for _, nt := range nutrients {
+			field := nt.field(&n)
+			if *field != nil {
+				continue
+			}
+			m := nt.pattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			value, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case nt.unit == "g" && strings.EqualFold(m[2], "mg"):
+				value /= 1000
+			case nt.unit == "mg" && strings.EqualFold(m[2], "g"):
+				value *= 1000
+			}
+			*field = &value
+			found = true
+		}
+	}
+	return n, found
+}
+
+// textLines returns the text of a page split at block elements, skipping
+// scripts and styles
+func textLines(doc *html.Node) []string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		if text := strings.Join(strings.Fields(line.String()), " "); text != "" {
+			lines = append(lines, text)
+		}
+		line.Reset()
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			line.WriteString(" ")
+			line.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+			if blockElements[n.Data] {
+				flush()
+				defer flush()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	flush()
+	return lines
+}
@@ -0,0 +1,117 @@
+package parser
+
+import "testing"
+
+// labelPage is a nutrition label laid out as a table, one nutrient per row
+const labelPage = `<html><body>
+<h2>Blueberry Muffin</h2>
+<table id="tblNutritionDetails">
+  <tr><td>Serving Size</td><td>1 muffin (113g)</td></tr>
+  <tr><td>Amount Per Serving</td></tr>
+  <tr><td><b>Calories</b> 420</td><td>Calories from Fat 160</td></tr>
+  <tr><td><b>Total Fat</b> 18g</td><td>23%</td></tr>
+  <tr><td>Saturated Fat 3.5g</td></tr>
+  <tr><td>Trans Fat 0g</td></tr>
+  <tr><td><b>Cholesterol</b> 0.07g</td></tr>
+  <tr><td><b>Sodium</b> 380mg</td></tr>
+  <tr><td><b>Total Carbohydrate</b> 58g</td></tr>
+  <tr><td>Dietary Fiber &lt;1g</td></tr>
+  <tr><td>Total Sugars 31g</td></tr>
+  <tr><td>Includes 12g Added Sugars</td></tr>
+  <tr><td><b>Protein</b> 6g</td></tr>
+</table>
+<script>var calories = 0;</script>
+</body></html>`
+
+func TestParseNutrition(t *testing.T) {
+	n, ok := ParseNutrition(labelPage)
+	if !ok {
+		t.Fatal("ParseNutrition() reported no label")
+	}
+	if n.ServingSize != "1 muffin (113g)" {
+		t.Errorf("ServingSize = %q", n.ServingSize)
+	}
+
+	tests := []struct {
+		name  string
+		value *float64
+		want  float64
+	}{
+		{"Calories", n.Calories, 420},
+		{"TotalFat", n.TotalFat, 18},
+		{"SaturatedFat", n.SaturatedFat, 3.5},
+		{"TransFat", n.TransFat, 0},
+		{"Cholesterol", n.Cholesterol, 70},
+		{"Sodium", n.Sodium, 380},
+		{"Carbohydrates", n.Carbohydrates, 58},
+		{"Fiber", n.Fiber, 1},
+		{"Sugars", n.Sugars, 31},
+		{"Protein", n.Protein, 6},
+	}
+	for _, tt := range tests {
+		if tt.value == nil {
+			t.Errorf("%s not parsed", tt.name)
+			continue
+		}
+		if *tt.value != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, *tt.value, tt.want)
+		}
+	}
+}
+
+func TestParseNutritionSingleLine(t *testing.T) {
+	n, ok := ParseNutrition(`<div class="label">Serving Size: 1 cup Calories: 250 Total Fat: 9g Sodium: 0.5g Protein: 12g</div>`)
+	if !ok {
+		t.Fatal("ParseNutrition() reported no label")
+	}
+	if n.ServingSize != "1 cup" {
+		t.Errorf("ServingSize = %q, want %q", n.ServingSize, "1 cup")
+	}
+	if n.Calories == nil || *n.Calories != 250 || n.TotalFat == nil || *n.TotalFat != 9 {
+		t.Errorf("Calories = %v, TotalFat = %v", n.Calories, n.TotalFat)
+	}
+	if n.Sodium == nil || *n.Sodium != 500 {
+		t.Errorf("Sodium = %v, want 500 (mg)", n.Sodium)
+	}
+	if n.Fiber != nil || n.Sugars != nil {
+		t.Error("nutrients missing from the label should stay nil")
+	}
+}
+
+func TestParseNutritionNoLabel(t *testing.T) {
+	for _, page := range []string{
+		"",
+		`<html><body><p>Item not found</p></body></html>`,
+		`<html><body><p>Calories from Fat</p></body></html>`,
+	} {
+		if n, ok := ParseNutrition(page); ok {
+			t.Errorf("ParseNutrition(%q) = %+v, want no label", page, n)
+		}
+	}
+}
+
+func TestExtractItemLink(t *testing.T) {
+	page := `<div id="MainContent_divMenu">
+		<li class="clsMenuItem"><a href="Label.aspx?id=101"><span class="clsLabel_Name">Oatmeal</span></a></li>
+		<li class="clsMenuItem"><span class="clsLabel_Name">Toast</span></li>
+	</div>`
+	items := DefaultRuleSet().ExtractItems(page, false)
+	if len(items) != 2 {
+		t.Fatalf("ExtractItems() = %+v, want 2 items", items)
+	}
+	if items[0].Link != "Label.aspx?id=101" {
+		t.Errorf("Link = %q, want the item's href", items[0].Link)
+	}
+	if items[1].Link != "" {
+		t.Errorf("Link = %q, want none for an item without a link", items[1].Link)
+	}
+
+	rs, err := NewRuleSet([]Rule{{Item: "a.dish", Fields: Fields{Link: "a"}}})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	items = rs.ExtractItems(`<a class="dish" href=" /label/7 ">Soup</a>`, false)
+	if len(items) != 1 || items[0].Link != "/label/7" {
+		t.Errorf("ExtractItems() = %+v, want the item element's own href", items)
+	}
+}
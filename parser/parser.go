@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"fmt"
+	"slices"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -55,195 +55,58 @@ func ExtractViewStateGenerator(htmlContent string) string {
 	return ExtractHiddenField(htmlContent, "__VIEWSTATEGENERATOR")
 }
 
-// ParseFoodItems parses HTML and extracts food menu items
-func ParseFoodItems(htmlContent string, debug bool) []string {
-	var foods []string
+// Option is a choice in an HTML <select> dropdown
+type Option struct {
+	Value string `json:"value"`
+	Text  string `json:"text"`
+}
+
+// ExtractSelectOptions returns the options of the <select> element with the given
+// name, in page order. Options without a value (placeholders like "-- Select --")
+// are skipped; an option without visible text uses its value as text.
+func ExtractSelectOptions(htmlContent, selectName string) []Option {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		return foods
+		return nil
 	}
 
-	seen := make(map[string]bool)
-
-	var traverse func(*html.Node)
-	traverse = func(n *html.Node) {
+	var options []Option
+	var traverse func(*html.Node, bool)
+	traverse = func(n *html.Node, inSelect bool) {
 		if n.Type == html.ElementNode {
-			// Check for td elements - try multiple strategies
-			if n.Data == "td" {
-				var hasMenuItemClass bool
-				var classValue string
-				for _, attr := range n.Attr {
-					if attr.Key == "class" {
-						classValue = attr.Val
-						lowerClass := strings.ToLower(attr.Val)
-						// Look for various class patterns
-						if strings.Contains(attr.Val, "MenuItem") ||
-							strings.Contains(lowerClass, "menu") ||
-							strings.Contains(lowerClass, "item") ||
-							strings.Contains(lowerClass, "food") ||
-							strings.Contains(lowerClass, "dish") ||
-							strings.Contains(lowerClass, "entry") {
-							hasMenuItemClass = true
-						}
-					}
-				}
-
-				// If no class match, still check td in table context (might be plain td)
-				foodText := extractTextFromNode(n)
-				foodName := strings.TrimSpace(foodText)
-
-				// Strip ingredients/allergens info if present
-				foodName = stripIngredientInfo(foodName)
-
-				if hasMenuItemClass || (foodName != "" && len(foodName) > 3 && len(foodName) < 100) {
-					// Filter out common non-food text
-					lowerFoodName := strings.ToLower(foodName)
-					// Skip if it's clearly not a food item
-					skip := false
-					if strings.HasPrefix(lowerFoodName, "menu") ||
-						strings.HasPrefix(lowerFoodName, "breakfast") ||
-						strings.HasPrefix(lowerFoodName, "lunch") ||
-						strings.HasPrefix(lowerFoodName, "dinner") ||
-						strings.HasPrefix(lowerFoodName, "brunch") ||
-						strings.HasPrefix(lowerFoodName, "dining hall") ||
-						strings.Contains(lowerFoodName, "select") ||
-						strings.Contains(lowerFoodName, "choose") ||
-						strings.Contains(lowerFoodName, "location") ||
-						strings.Contains(lowerFoodName, "date") ||
-						strings.Contains(lowerFoodName, "ingredient") ||
-						strings.Contains(lowerFoodName, "allergen") ||
-						strings.Contains(lowerFoodName, "allergy") ||
-						strings.Contains(lowerFoodName, "made on shared") ||
-						len(foodName) <= 2 {
-						skip = true
-					}
-
-					// Only add if we have a class match AND not skipped
-					if hasMenuItemClass && !skip && !seen[foodName] {
-						// Additional check: must not look like ingredient/allergen text
-						if !looksLikeIngredientText(foodName) {
-							if debug {
-								fmt.Printf("DEBUG: Found food item via class '%s': %s\n", classValue, foodName)
-							}
-							foods = append(foods, foodName)
-							seen[foodName] = true
-						} else if debug {
-							fmt.Printf("DEBUG: Skipped ingredient-like text: %s\n", foodName)
-						}
-					}
-				}
-			}
-
-			// Also check for div elements that might contain menu items
-			if n.Data == "div" {
-				for _, attr := range n.Attr {
-					if attr.Key == "class" {
-						lowerClass := strings.ToLower(attr.Val)
-						if strings.Contains(attr.Val, "MenuItem") ||
-							strings.Contains(lowerClass, "menu-item") ||
-							strings.Contains(lowerClass, "food-item") {
-							foodText := extractTextFromNode(n)
-							foodName := strings.TrimSpace(foodText)
-							if foodName != "" && len(foodName) > 2 && !seen[foodName] {
-								foods = append(foods, foodName)
-								seen[foodName] = true
-							}
-							break
+			switch n.Data {
+			case "select":
+				inSelect = getAttr(n, "name") == selectName
+			case "option":
+				if inSelect {
+					value := strings.TrimSpace(getAttr(n, "value"))
+					text := strings.TrimSpace(extractTextFromNode(n))
+					if value != "" {
+						if text == "" {
+							text = value
 						}
+						options = append(options, Option{Value: value, Text: text})
 					}
-				}
-			}
-
-			// Check for span elements
-			if n.Data == "span" {
-				for _, attr := range n.Attr {
-					if attr.Key == "class" && strings.Contains(strings.ToLower(attr.Val), "item") {
-						foodText := extractTextFromNode(n)
-						foodName := strings.TrimSpace(foodText)
-						if foodName != "" && len(foodName) > 2 && !seen[foodName] {
-							foods = append(foods, foodName)
-							seen[foodName] = true
-						}
-						break
-					}
-				}
-			}
-
-			// Check for h3 elements with clsLabel_Name (main food items)
-			if n.Data == "h3" {
-				for _, attr := range n.Attr {
-					if attr.Key == "class" && strings.Contains(attr.Val, "clsLabel_Name") {
-						foodText := extractTextFromNode(n)
-						foodName := strings.TrimSpace(foodText)
-						foodName = stripIngredientInfo(foodName)
-
-						if foodName != "" && len(foodName) > 2 && !looksLikeIngredientText(foodName) && !seen[foodName] {
-							if debug {
-								fmt.Printf("DEBUG: Found food item in h3: %s\n", foodName)
-							}
-							foods = append(foods, foodName)
-							seen[foodName] = true
-						}
-						break
-					}
-				}
-			}
-
-			// Check for list items (li) - menus are often in lists
-			if n.Data == "li" {
-				foodText := extractTextFromNode(n)
-				foodName := strings.TrimSpace(foodText)
-				foodName = stripIngredientInfo(foodName)
-				lowerFoodName := strings.ToLower(foodName)
-				// Filter out navigation and non-food items
-				if foodName != "" && len(foodName) > 3 && len(foodName) < 100 &&
-					!strings.HasPrefix(lowerFoodName, "menu") &&
-					!strings.HasPrefix(lowerFoodName, "breakfast") &&
-					!strings.HasPrefix(lowerFoodName, "lunch") &&
-					!strings.HasPrefix(lowerFoodName, "dinner") &&
-					!strings.HasPrefix(lowerFoodName, "brunch") &&
-					!strings.Contains(lowerFoodName, "select") &&
-					!strings.Contains(lowerFoodName, "location") &&
-					!strings.Contains(lowerFoodName, "date") &&
-					!strings.ContainsAny(foodName, "{}[]()|\\/") &&
-					!seen[foodName] {
-					if debug {
-						fmt.Printf("DEBUG: Found food item in list: %s\n", foodName)
-					}
-					foods = append(foods, foodName)
-					seen[foodName] = true
+					return
 				}
 			}
 		}
-
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			traverse(c)
+			traverse(c, inSelect)
 		}
 	}
-	traverse(doc)
+	traverse(doc, false)
 
-	return foods
+	return options
 }
 
-func stripIngredientInfo(text string) string {
-	// Strip everything after "Ingredients:" or "Allergens:"
-	if idx := strings.Index(text, " Ingredients:"); idx != -1 {
-		text = text[:idx]
-	}
-	if idx := strings.Index(text, " Allergens:"); idx != -1 {
-		text = text[:idx]
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
 	}
-	return strings.TrimSpace(text)
-}
-
-func looksLikeIngredientText(text string) bool {
-	lower := strings.ToLower(text)
-	// After stripping, check if what's left is just ingredient/allergen keywords
-	return lower == "ingredients" ||
-		lower == "allergens" ||
-		lower == "allergy" ||
-		strings.HasPrefix(lower, "made on shared") ||
-		(len(text) > 0 && strings.Contains(lower, "chef's choice") && !strings.Contains(lower, " "))
+	return ""
 }
 
 func getParentTableElement(n *html.Node) *html.Node {
@@ -256,9 +119,17 @@ func getParentTableElement(n *html.Node) *html.Node {
 }
 
 func extractTextFromNode(n *html.Node) string {
+	return extractText(n, nil)
+}
+
+// extractText joins the text under n, leaving out the subtrees rooted at skip
+func extractText(n *html.Node, skip []*html.Node) string {
 	var textBuilder strings.Builder
-	var extractText func(*html.Node)
-	extractText = func(node *html.Node) {
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if slices.Contains(skip, node) {
+			return
+		}
 		if node.Type == html.TextNode {
 			text := strings.TrimSpace(node.Data)
 			if text != "" {
@@ -269,9 +140,9 @@ func extractTextFromNode(n *html.Node) string {
 			}
 		}
 		for c := node.FirstChild; c != nil; c = c.NextSibling {
-			extractText(c)
+			walk(c)
 		}
 	}
-	extractText(n)
+	walk(n)
 	return textBuilder.String()
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -301,3 +302,38 @@ func TestParseFoodItemsWithWhitespace(t *testing.T) {
 		t.Errorf("ParseFoodItems() should preserve food name, got %v", foodText)
 	}
 }
+
+func TestExtractSelectOptions(t *testing.T) {
+	page := `<form>
+		<select name="ctl00$MainContent$lstLocations" id="MainContent_lstLocations">
+			<option value="">-- Select a Location --</option>
+			<option selected="selected" value="Arrillaga">Arrillaga Family Dining Commons</option>
+			<option value="Branner">Branner Dining</option>
+			<option value="NewHall"> New &amp; Improved Hall </option>
+		</select>
+		<select name="ctl00$MainContent$lstMealType">
+			<option value="Breakfast">Breakfast</option>
+			<option value="Lunch"></option>
+		</select>
+	</form>`
+
+	locations := ExtractSelectOptions(page, "ctl00$MainContent$lstLocations")
+	want := []Option{
+		{Value: "Arrillaga", Text: "Arrillaga Family Dining Commons"},
+		{Value: "Branner", Text: "Branner Dining"},
+		{Value: "NewHall", Text: "New & Improved Hall"},
+	}
+	if !reflect.DeepEqual(locations, want) {
+		t.Errorf("ExtractSelectOptions(lstLocations) = %v, want %v", locations, want)
+	}
+
+	mealTypes := ExtractSelectOptions(page, "ctl00$MainContent$lstMealType")
+	wantMeals := []Option{{Value: "Breakfast", Text: "Breakfast"}, {Value: "Lunch", Text: "Lunch"}}
+	if !reflect.DeepEqual(mealTypes, wantMeals) {
+		t.Errorf("ExtractSelectOptions(lstMealType) = %v, want %v", mealTypes, wantMeals)
+	}
+
+	if got := ExtractSelectOptions(page, "missing"); len(got) != 0 {
+		t.Errorf("ExtractSelectOptions(missing) = %v, want none", got)
+	}
+}
@@ -0,0 +1,542 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Rule describes one way menu items appear in a page: which elements hold an
+// item, where its fields are, and which text is not a dish
+type Rule struct {
+	// Name identifies the rule in debug output and extracted items
+	Name string `json:"name"`
+	// Item is a selector for elements holding one menu item each, like `td.MenuItem`
+	Item string `json:"item"`
+	// Within restricts the rule to items inside an element matching this
+	// selector, such as the menu container
+	Within string `json:"within,omitempty"`
+	// Fallback rules run only when the primary rules found no items and the
+	// page has none of their Within containers, as when the site's markup changed
+	Fallback bool `json:"fallback,omitempty"`
+	// Confidence scores the items this rule extracts, from 0 to 1; zero means 1
+	Confidence float64 `json:"confidence,omitempty"`
+	// Fields locate parts of the item inside the item element
+	Fields Fields `json:"fields,omitempty"`
+	// StripAfter cuts the name at the first of these markers, like " Ingredients:"
+	StripAfter []string `json:"stripAfter,omitempty"`
+	// MinLength and MaxLength bound the name's length in bytes; zero means no bound
+	MinLength int `json:"minLength,omitempty"`
+	MaxLength int `json:"maxLength,omitempty"`
+	// Exclude rejects names that are page furniture rather than dishes
+	Exclude Exclusions `json:"exclude,omitempty"`
+}
+
+// Fields are selectors matched against an item element's descendants.
+// Without a Name selector the item's own text is the name, minus the text
+// of any ingredient or allergen fields.
+type Fields struct {
+	Name        string `json:"name,omitempty"`
+	Ingredients string `json:"ingredients,omitempty"`
+	Allergens   string `json:"allergens,omitempty"`
+	// Link selects the item's detail link; the item element itself may match
+	Link string `json:"link,omitempty"`
+}
+
+// Exclusions reject an item by its name or its place in the page.
+// Name tests are case-insensitive, except Chars.
+type Exclusions struct {
+	Exact    []string `json:"exact,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+	Contains []string `json:"contains,omitempty"`
+	// Patterns are regular expressions, like `\bselect\b`
+	Patterns []string `json:"patterns,omitempty"`
+	// Chars rejects names containing any of these characters, matched exactly
+	Chars string `json:"chars,omitempty"`
+	// Within rejects items inside an element matching this selector, like "nav, footer"
+	Within string `json:"within,omitempty"`
+	// Has rejects items containing an element matching this selector, like "a[href]"
+	Has string `json:"has,omitempty"`
+}
+
+// Item is a menu item extracted by a rule
+type Item struct {
+	Name        string `json:"name"`
+	Ingredients string `json:"ingredients,omitempty"`
+	Allergens   string `json:"allergens,omitempty"`
+	// Link is the href of the item's detail page, as written in the page
+	Link string `json:"link,omitempty"`
+	Rule string `json:"rule,omitempty"`
+	// Confidence is how likely the item is a real dish, from 0 to 1
+	Confidence float64 `json:"confidence"`
+	// Nutrition is the item's nutrition label, if it was fetched
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+}
+
+// FindItem returns the item with a name, ignoring case and surrounding space
+func FindItem(items []Item, name string) (Item, bool) {
+	name = strings.TrimSpace(name)
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// MenuContainer selects the element of the menu site's page holding the menu
+const MenuContainer = "#MainContent_divMenu"
+
+// noMenu selects the notice the menu site puts in the menu container when a
+// meal isn't served
+var noMenu, _ = parseSelector(".clsNoMenu")
+
+// ingredientMarkers start the ingredient and allergen text the menu site
+// appends to item names
+var ingredientMarkers = []string{" Ingredients:", " Allergens:"}
+
+// pageFurniture are patterns for the labels, dates and notices around a menu
+var pageFurniture = []string{
+	`\b(select|choose)\b`,
+	`^(date|day|location|meal|meal type)s?\s*(:|$)`,
+	`^\d{1,2}/\d{1,2}(/\d{2,4})?$`,
+	`^(mon|tues|wednes|thurs|fri|satur|sun)day,?\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{1,2}\b`,
+	`©|\bcopyright\b|\ball rights reserved\b`,
+}
+
+// Page regions and elements that never hold menu items
+const (
+	navigationRegions  = "nav, header, footer, select, [role=navigation], [role=banner], [role=contentinfo], [class*=footer i], [class*=breadcrumb i]"
+	navigationElements = "a[href], input, select, button"
+)
+
+// DefaultRules returns the rules used when none are configured. The primary
+// rules read items from the menu site's menu container; the fallback rules
+// are the older page-wide heuristics, kept for when the site's markup changes.
+func DefaultRules() []Rule {
+	labelExclusions := func() Exclusions {
+		return Exclusions{
+			Exact:    []string{"ingredients", "allergens", "allergy"},
+			Prefixes: []string{"made on shared"},
+		}
+	}
+	fallbackExclusions := func(prefixes ...string) Exclusions {
+		return Exclusions{
+			Prefixes: prefixes,
+			Contains: []string{"ingredient", "allergen", "allergy", "made on shared"},
+			Patterns: slices.Clone(pageFurniture),
+			Within:   navigationRegions,
+			Has:      navigationElements,
+		}
+	}
+	listExclusions := fallbackExclusions("menu", "breakfast", "lunch", "dinner", "brunch")
+	listExclusions.Chars = `{}[]()|\/`
+
+	return []Rule{
+		{
+			Name:       "menu-item",
+			Item:       ".clsMenuItem",
+			Within:     MenuContainer,
+			Confidence: 1,
+			Fields: Fields{
+				Name:        ".clsLabel_Name",
+				Ingredients: ".clsLabel_Ingredients",
+				Allergens:   ".clsLabel_Allergens",
+				Link:        "a[href]",
+			},
+			StripAfter: slices.Clone(ingredientMarkers),
+			MinLength:  2,
+			Exclude:    labelExclusions(),
+		},
+		{
+			Name:       "menu-label",
+			Item:       "[class*=clsLabel_Name]",
+			Within:     MenuContainer,
+			Confidence: 0.9,
+			StripAfter: slices.Clone(ingredientMarkers),
+			MinLength:  2,
+			Exclude:    labelExclusions(),
+		},
+		{
+			Name:       "page-label",
+			Item:       "h3[class*=clsLabel_Name]",
+			Fallback:   true,
+			Confidence: 0.7,
+			StripAfter: slices.Clone(ingredientMarkers),
+			MinLength:  3,
+			Exclude:    labelExclusions(),
+		},
+		{
+			Name:       "table-cell",
+			Item:       "td[class*=menu i], td[class*=item i], td[class*=food i], td[class*=dish i], td[class*=entry i]",
+			Fallback:   true,
+			Confidence: 0.6,
+			StripAfter: slices.Clone(ingredientMarkers),
+			MinLength:  3,
+			Exclude:    fallbackExclusions("menu", "breakfast", "lunch", "dinner", "brunch", "dining hall"),
+		},
+		{
+			Name:       "item-div",
+			Item:       "div[class*=MenuItem], div[class*=menu-item i], div[class*=food-item i]",
+			Fallback:   true,
+			Confidence: 0.6,
+			MinLength:  3,
+			Exclude:    fallbackExclusions(),
+		},
+		{
+			Name:       "item-span",
+			Item:       "span[class*=item i]",
+			Fallback:   true,
+			Confidence: 0.5,
+			MinLength:  3,
+			Exclude:    fallbackExclusions(),
+		},
+		{
+			Name:       "list-item",
+			Item:       "li",
+			Fallback:   true,
+			Confidence: 0.4,
+			StripAfter: slices.Clone(ingredientMarkers),
+			MinLength:  3,
+			MaxLength:  99,
+			Exclude:    listExclusions,
+		},
+	}
+}
+
+// RuleSet is a validated, ready-to-use list of rules. For each element the
+// first rule that applies decides whether it is a menu item; items are
+// returned in page order without duplicate names.
+type RuleSet struct {
+	primary  []compiledRule
+	fallback []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	item          selector
+	within        selector
+	name          selector
+	ingredients   selector
+	allergens     selector
+	link          selector
+	excludeWithin selector
+	excludeHas    selector
+	patterns      []*regexp.Regexp
+}
+
+var defaultRuleSet = mustRuleSet(DefaultRules())
+
+// DefaultRuleSet returns the rule set built from DefaultRules
+func DefaultRuleSet() *RuleSet {
+	return defaultRuleSet
+}
+
+// NewRuleSet validates rules and parses their selectors and patterns
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no parsing rules")
+	}
+
+	var errs []error
+	rs := &RuleSet{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Confidence == 0 {
+			rule.Confidence = 1
+		}
+		c := compiledRule{Rule: rule}
+		if rule.Item == "" {
+			errs = append(errs, fmt.Errorf("%s: item selector is required", rule.Name))
+			continue
+		}
+		if rule.MinLength < 0 || rule.MaxLength < 0 || (rule.MaxLength > 0 && rule.MaxLength < rule.MinLength) {
+			errs = append(errs, fmt.Errorf("%s: invalid length bounds %d-%d", rule.Name, rule.MinLength, rule.MaxLength))
+		}
+		if rule.Confidence < 0 || rule.Confidence > 1 {
+			errs = append(errs, fmt.Errorf("%s: confidence must be between 0 and 1", rule.Name))
+		}
+		for _, field := range []struct {
+			src string
+			dst *selector
+		}{
+			{rule.Item, &c.item},
+			{rule.Within, &c.within},
+			{rule.Fields.Name, &c.name},
+			{rule.Fields.Ingredients, &c.ingredients},
+			{rule.Fields.Allergens, &c.allergens},
+			{rule.Fields.Link, &c.link},
+			{rule.Exclude.Within, &c.excludeWithin},
+			{rule.Exclude.Has, &c.excludeHas},
+		} {
+			if field.src == "" {
+				continue
+			}
+			sel, err := parseSelector(field.src)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", rule.Name, err))
+				continue
+			}
+			*field.dst = sel
+		}
+		for _, pattern := range rule.Exclude.Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern %q: %w", rule.Name, pattern, err))
+				continue
+			}
+			c.patterns = append(c.patterns, re)
+		}
+		if rule.Fallback {
+			rs.fallback = append(rs.fallback, c)
+		} else {
+			rs.primary = append(rs.primary, c)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func mustRuleSet(rules []Rule) *RuleSet {
+	rs, err := NewRuleSet(rules)
+	if err != nil {
+		panic(err)
+	}
+	return rs
+}
+
+// ParseFoodItems parses HTML and extracts food menu items using the default rules
+func ParseFoodItems(htmlContent string, debug bool) []string {
+	return defaultRuleSet.ParseFoodItems(htmlContent, debug)
+}
+
+// ParseFoodItems parses HTML and returns the names of the menu items found
+func (rs *RuleSet) ParseFoodItems(htmlContent string, debug bool) []string {
+	var foods []string
+	for _, item := range rs.ExtractItems(htmlContent, debug) {
+		foods = append(foods, item.Name)
+	}
+	return foods
+}
+
+// Extraction is the outcome of running a rule set over a page
+type Extraction struct {
+	Items []Item `json:"items"`
+	// Container reports whether the page had a primary rule's container
+	Container bool `json:"container"`
+	// Closed reports whether the page had no items because the meal isn't
+	// served: its containers are empty or hold the site's no-menu notice.
+	// Containers with other content but no items are not closed.
+	Closed bool `json:"closed"`
+	// Fallback reports whether the fallback rules were used
+	Fallback bool `json:"fallback"`
+}
+
+// ExtractItems parses HTML and returns the menu items found, in page order
+func (rs *RuleSet) ExtractItems(htmlContent string, debug bool) []Item {
+	return rs.Extract(htmlContent, debug).Items
+}
+
+// Extract parses HTML and returns the menu items found along with how they
+// were found. The fallback rules are tried only if the primary rules found
+// nothing and the page has none of their containers: a menu container without
+// items is an empty menu, not a reason to search the rest of the page.
+func (rs *RuleSet) Extract(htmlContent string, debug bool) Extraction {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return Extraction{}
+	}
+
+	var result Extraction
+	items, containers := extractItems(doc, rs.primary, debug)
+	result.Items, result.Container = items, len(containers) > 0
+	if len(result.Items) == 0 && result.Container {
+		result.Closed = !slices.ContainsFunc(containers, func(n *html.Node) bool {
+			return noMenu.first(n) == nil && extractTextFromNode(n) != ""
+		})
+	}
+	if len(result.Items) == 0 && !result.Container && len(rs.fallback) > 0 {
+		if debug {
+			fmt.Println("DEBUG: No menu container found, trying fallback rules")
+		}
+		result.Items, _ = extractItems(doc, rs.fallback, debug)
+		result.Fallback = true
+	}
+	return result
+}
+
+// extractItems applies rules to every element of doc, also returning the
+// elements that matched a rule's Within container
+func extractItems(doc *html.Node, rules []compiledRule, debug bool) ([]Item, []*html.Node) {
+	var items []Item
+	var containers []*html.Node
+	seen := make(map[string]bool)
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, rule := range rules {
+				if rule.within != nil && rule.within.matches(n) && !slices.Contains(containers, n) {
+					containers = append(containers, n)
+				}
+			}
+			for _, rule := range rules {
+				if !rule.matches(n) {
+					continue
+				}
+				item, ok := rule.extract(n)
+				if ok && !seen[item.Name] {
+					if debug {
+						fmt.Printf("DEBUG: Found food item via rule %s (confidence %.2f): %s\n", rule.Name, item.Confidence, item.Name)
+					}
+					items = append(items, item)
+					seen[item.Name] = true
+				}
+				break
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	return items, containers
+}
+
+// matches reports whether the rule applies to an element
+func (r compiledRule) matches(n *html.Node) bool {
+	return r.item.matches(n) && (r.within == nil || hasAncestor(n, r.within))
+}
+
+// extract reads an item from an element the rule applies to, reporting false
+// if the rule rejects it
+func (r compiledRule) extract(n *html.Node) (Item, bool) {
+	item := Item{Rule: r.Name}
+	if (r.excludeWithin != nil && hasAncestor(n, r.excludeWithin)) ||
+		(r.excludeHas != nil && r.excludeHas.first(n) != nil) {
+		return item, false
+	}
+
+	var skip []*html.Node
+	if r.ingredients != nil {
+		if field := r.ingredients.first(n); field != nil {
+			item.Ingredients = trimLabel(extractTextFromNode(field))
+			skip = append(skip, field)
+		}
+	}
+	if r.allergens != nil {
+		if field := r.allergens.first(n); field != nil {
+			item.Allergens = trimLabel(extractTextFromNode(field))
+			skip = append(skip, field)
+		}
+	}
+
+	if r.link != nil {
+		link := n
+		if !r.link.matches(n) {
+			link = r.link.first(n)
+		}
+		if link != nil {
+			item.Link = strings.TrimSpace(getAttr(link, "href"))
+		}
+	}
+
+	nameNode := n
+	if r.name != nil {
+		if nameNode = r.name.first(n); nameNode == nil {
+			return item, false
+		}
+	}
+	name := strings.TrimSpace(extractText(nameNode, skip))
+	if idx := firstIndex(name, r.StripAfter); idx != -1 {
+		name = strings.TrimSpace(name[:idx])
+	}
+	item.Name = name
+	item.Confidence = r.score(item)
+
+	return item, name != "" && r.accepts(name)
+}
+
+// accepts applies the rule's length bounds and name exclusions
+func (r compiledRule) accepts(name string) bool {
+	if len(name) < r.MinLength || (r.MaxLength > 0 && len(name) > r.MaxLength) {
+		return false
+	}
+	lower := strings.ToLower(name)
+	for _, exact := range r.Exclude.Exact {
+		if lower == strings.ToLower(exact) {
+			return false
+		}
+	}
+	for _, prefix := range r.Exclude.Prefixes {
+		if strings.HasPrefix(lower, strings.ToLower(prefix)) {
+			return false
+		}
+	}
+	for _, substr := range r.Exclude.Contains {
+		if strings.Contains(lower, strings.ToLower(substr)) {
+			return false
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	return !strings.ContainsAny(name, r.Exclude.Chars)
+}
+
+// score starts from the rule's confidence, discounts names that read like
+// sentences rather than dishes and credits items with ingredients or allergens
+func (r compiledRule) score(item Item) float64 {
+	score := r.Confidence
+	if len(strings.Fields(item.Name)) > 8 || strings.HasSuffix(item.Name, ".") ||
+		strings.HasSuffix(item.Name, "!") || strings.HasSuffix(item.Name, "?") {
+		score *= 0.5
+	}
+	if item.Ingredients != "" || item.Allergens != "" {
+		score = min(1, score+0.1)
+	}
+	return math.Round(score*100) / 100
+}
+
+// hasAncestor reports whether any ancestor of n matches the selector
+func hasAncestor(n *html.Node, sel selector) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if sel.matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimLabel removes a leading one-word label like "Allergens:" from field text
+func trimLabel(text string) string {
+	text = strings.TrimSpace(text)
+	if label, rest, ok := strings.Cut(text, ":"); ok && label != "" && !strings.ContainsAny(label, " \t") {
+		return strings.TrimSpace(rest)
+	}
+	return text
+}
+
+// firstIndex returns the index of the earliest marker in s, or -1
+func firstIndex(s string, markers []string) int {
+	first := -1
+	for _, marker := range markers {
+		if idx := strings.Index(s, marker); idx != -1 && (first == -1 || idx < first) {
+			first = idx
+		}
+	}
+	return first
+}
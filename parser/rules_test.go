@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRuleSetFallback(t *testing.T) {
+	// Without the menu container, the fallback rules search the whole page
+	html := `<html><body>
+		<h3 class="clsLabel_Name">Grilled Salmon Ingredients: salmon, lemon</h3>
+		<table><tr><td class="MenuItem">Breakfast Menu</td><td class="MenuItem">Oatmeal</td></tr></table>
+		<ul><li>Fruit Salad</li><li>Select a date</li><li>Go</li></ul>
+	</body></html>`
+
+	items := DefaultRuleSet().ExtractItems(html, false)
+	want := []Item{
+		{Name: "Grilled Salmon", Rule: "page-label", Confidence: 0.7},
+		{Name: "Oatmeal", Rule: "table-cell", Confidence: 0.6},
+		{Name: "Fruit Salad", Rule: "list-item", Confidence: 0.4},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("ExtractItems() = %+v, want %+v", items, want)
+	}
+}
+
+func TestRuleSetFromJSON(t *testing.T) {
+	var rules []Rule
+	err := json.Unmarshal([]byte(`[{
+		"name": "card",
+		"item": "article.dish",
+		"fields": {"name": ".title", "ingredients": ".ingredients", "allergens": ".allergens"},
+		"minLength": 3,
+		"exclude": {"exact": ["Closed"], "contains": ["station"]}
+	}]`), &rules)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	rs, err := NewRuleSet(rules)
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	html := `<main>
+		<article class="dish"><h4 class="title">Pad Thai</h4><p class="ingredients">rice noodles, tofu</p><p class="allergens">peanuts, soy</p></article>
+		<article class="dish"><h4 class="title">closed</h4></article>
+		<article class="dish"><h4 class="title">Grill Station</h4></article>
+		<article class="dish"><p>No title here</p></article>
+		<td class="MenuItem">Not matched by custom rules</td>
+	</main>`
+	items := rs.ExtractItems(html, false)
+	want := []Item{{Name: "Pad Thai", Ingredients: "rice noodles, tofu", Allergens: "peanuts, soy", Rule: "card", Confidence: 1}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("ExtractItems() = %+v, want %+v", items, want)
+	}
+}
+
+func TestRuleFieldsExcludedFromName(t *testing.T) {
+	rs, err := NewRuleSet([]Rule{{
+		Item:   "div.item",
+		Fields: Fields{Ingredients: "small", Allergens: "em"},
+	}})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	items := rs.ExtractItems(`<div class="item">Chili <small>beans, beef</small> <em>none</em></div>`, false)
+	if len(items) != 1 {
+		t.Fatalf("ExtractItems() = %+v, want one item", items)
+	}
+	if items[0].Name != "Chili" || items[0].Ingredients != "beans, beef" || items[0].Allergens != "none" {
+		t.Errorf("ExtractItems() = %+v", items[0])
+	}
+	if items[0].Rule != "rule 1" {
+		t.Errorf("Rule = %q, want default name \"rule 1\"", items[0].Rule)
+	}
+}
+
+func TestRuleFirstMatchDecides(t *testing.T) {
+	rs, err := NewRuleSet([]Rule{
+		{Name: "strict", Item: "li.special", MinLength: 10},
+		{Name: "loose", Item: "li"},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	got := rs.ParseFoodItems(`<ul><li class="special">Soup</li><li>Bread</li></ul>`, false)
+	if !slices.Equal(got, []string{"Bread"}) {
+		t.Errorf("ParseFoodItems() = %v, want only Bread: the strict rule rejects Soup", got)
+	}
+}
+
+func TestRuleLengthAndChars(t *testing.T) {
+	rs, err := NewRuleSet([]Rule{{
+		Item:       "li",
+		StripAfter: []string{" Allergens:", " Ingredients:"},
+		MinLength:  4,
+		MaxLength:  12,
+		Exclude:    Exclusions{Prefixes: []string{"MENU"}, Chars: "|"},
+	}})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	html := `<ul>
+		<li>Tea</li>
+		<li>Lemonade Ingredients: lemon Allergens: none</li>
+		<li>Very Long Dish Name</li>
+		<li>Menu Home</li>
+		<li>Home | About</li>
+	</ul>`
+	got := rs.ParseFoodItems(html, false)
+	if !slices.Equal(got, []string{"Lemonade"}) {
+		t.Errorf("ParseFoodItems() = %v, want [Lemonade]", got)
+	}
+}
+
+func TestNewRuleSetErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		want  string
+	}{
+		{"no rules", nil, "no parsing rules"},
+		{"missing item", []Rule{{Name: "empty"}}, "empty: item selector is required"},
+		{"bad item selector", []Rule{{Name: "bad", Item: "td["}}, "bad: invalid selector"},
+		{"bad field selector", []Rule{{Item: "td", Fields: Fields{Name: "."}}}, "rule 1: invalid selector"},
+		{"bad bounds", []Rule{{Name: "len", Item: "td", MinLength: 10, MaxLength: 5}}, "len: invalid length bounds"},
+		{"bad confidence", []Rule{{Name: "sure", Item: "td", Confidence: 1.5}}, "sure: confidence must be between 0 and 1"},
+		{"bad pattern", []Rule{{Name: "re", Item: "td", Exclude: Exclusions{Patterns: []string{"("}}}}, "re: invalid pattern"},
+		{"bad exclusion selector", []Rule{{Name: "guard", Item: "td", Exclude: Exclusions{Has: "a["}}}, "guard: invalid selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleSet(tt.rules)
+			if err == nil {
+				t.Fatal("NewRuleSet() should return error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewRuleSet() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractItemsMenuContainer(t *testing.T) {
+	html := `<ul><li>Dining Halls</li></ul>
+		<div id="MainContent_divMenu">
+			<div class="clsMenuItem">
+				<h3 class="clsLabel_Name">Date Nut Bread</h3>
+				<span class="clsLabel_Ingredients">Ingredients: dates, walnuts</span>
+				<span class="clsLabel_Allergens">Allergens: tree nuts</span>
+			</div>
+			<h3 class="clsLabel_Name">Oatmeal</h3>
+		</div>`
+
+	items := DefaultRuleSet().ExtractItems(html, false)
+	want := []Item{
+		{Name: "Date Nut Bread", Ingredients: "dates, walnuts", Allergens: "tree nuts", Rule: "menu-item", Confidence: 1},
+		{Name: "Oatmeal", Rule: "menu-label", Confidence: 0.9},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("ExtractItems() = %+v, want %+v", items, want)
+	}
+}
+
+func TestExtractItemsEmptyContainerSkipsFallback(t *testing.T) {
+	html := `<ul><li>Dining Halls</li><li>Weekly Menus</li></ul>
+		<div id="MainContent_divMenu"><p>No menu items are available.</p></div>`
+	if items := DefaultRuleSet().ExtractItems(html, false); len(items) != 0 {
+		t.Errorf("ExtractItems() = %+v, want none: an empty menu container is an empty menu", items)
+	}
+}
+
+func TestExtractClosed(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"empty container", `<div id="MainContent_divMenu">  </div>`, true},
+		{"no-menu notice", `<div id="MainContent_divMenu"><span class="clsNoMenu">No menu is available.</span></div>`, true},
+		{"unrecognized items", `<div id="MainContent_divMenu"><li class="clsDish">Pho</li></div>`, false},
+		{"items", `<div id="MainContent_divMenu"><h3 class="clsLabel_Name">Soup</h3></div>`, false},
+		{"no container", `<p>Menus</p>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRuleSet().Extract(tt.html, false); got.Closed != tt.want {
+				t.Errorf("Extract() = %+v, want Closed %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackGuards(t *testing.T) {
+	html := `<nav><ul><li>Dining Halls</li></ul></nav>
+		<ul>
+			<li><a href="/hours">Hours and Locations</a></li>
+			<li>Date Nut Bread</li>
+			<li>Chef's Selection Pasta</li>
+			<li>Select a date</li>
+			<li>Date: 11/4/2024</li>
+			<li>Monday, November 4</li>
+			<li>11/4/2024</li>
+			<li>© 2024 Stanford University</li>
+		</ul>
+		<div class="site-footer"><ul><li>Contact Us</li></ul></div>`
+
+	got := ParseFoodItems(html, false)
+	want := []string{"Date Nut Bread", "Chef's Selection Pasta"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseFoodItems() = %q, want %q", got, want)
+	}
+}
+
+func TestItemConfidence(t *testing.T) {
+	rs, err := NewRuleSet([]Rule{{Item: "li", Confidence: 0.8, Fields: Fields{Allergens: "em"}}})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	html := `<ul>
+		<li>Roast Turkey</li>
+		<li>Gravy <em>wheat</em></li>
+		<li>Please ask a team member about today's specials and substitutions.</li>
+	</ul>`
+	confidence := make(map[string]float64)
+	for _, item := range rs.ExtractItems(html, false) {
+		confidence[item.Name] = item.Confidence
+	}
+	want := map[string]float64{
+		"Roast Turkey": 0.8,
+		"Gravy":        0.9,
+		"Please ask a team member about today's specials and substitutions.": 0.4,
+	}
+	if !reflect.DeepEqual(confidence, want) {
+		t.Errorf("confidence = %v, want %v", confidence, want)
+	}
+}
+
+func TestExtractReportsFallback(t *testing.T) {
+	withContainer := DefaultRuleSet().Extract(`<div id="MainContent_divMenu"><h3 class="clsLabel_Name">Soup</h3></div>`, false)
+	if !withContainer.Container || withContainer.Fallback || len(withContainer.Items) != 1 {
+		t.Errorf("Extract() = %+v, want one item from the container", withContainer)
+	}
+
+	without := DefaultRuleSet().Extract(`<table><tr><td class="MenuItem">Soup</td></tr></table>`, false)
+	if without.Container || !without.Fallback || len(without.Items) != 1 {
+		t.Errorf("Extract() = %+v, want one item from the fallback rules", without)
+	}
+}
+
+func TestFindItem(t *testing.T) {
+	items := []Item{{Name: "Oatmeal"}, {Name: "Chana Masala", Allergens: "none"}}
+	if item, ok := FindItem(items, " chana masala "); !ok || item.Allergens != "none" {
+		t.Errorf("FindItem() = %+v, %v, want Chana Masala", item, ok)
+	}
+	if _, ok := FindItem(items, "Masala"); ok {
+		t.Error("FindItem() should match whole names only")
+	}
+}
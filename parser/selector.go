@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// selector is a parsed CSS-like selector list. It supports tag names, "*",
+// .class, #id, attribute tests ([attr], =, ~=, *=, ^=, $=, with an "i" flag for
+// case-insensitive matching), descendant combinators and comma-separated alternatives.
+type selector []complexSelector
+
+// complexSelector is a chain of compound selectors joined by descendant
+// combinators, outermost ancestor first
+type complexSelector []compoundSelector
+
+type compoundSelector struct {
+	tag   string // empty matches any element
+	attrs []attrSelector
+}
+
+type attrSelector struct {
+	key   string
+	op    string // "" tests presence
+	value string
+	fold  bool // compare case-insensitively
+}
+
+// parseSelector parses a selector list such as `td.MenuItem, div[class*=food i]`
+func parseSelector(s string) (selector, error) {
+	p := &selectorParser{s: s}
+	var sel selector
+	for {
+		p.skipSpace()
+		complex, err := p.parseComplex()
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		sel = append(sel, complex)
+		p.skipSpace()
+		if p.done() {
+			return sel, nil
+		}
+		if p.s[p.pos] != ',' {
+			return nil, fmt.Errorf("invalid selector %q: unexpected %q at offset %d", s, p.s[p.pos], p.pos)
+		}
+		p.pos++
+	}
+}
+
+// matches reports whether the element matches any alternative
+func (s selector) matches(n *html.Node) bool {
+	for _, complex := range s {
+		if complex.matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the first descendant of n, in document order, matching the selector
+func (s selector) first(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if s.matches(c) {
+			return c
+		}
+		if found := s.first(c); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (c complexSelector) matches(n *html.Node) bool {
+	last := len(c) - 1
+	if !c[last].matches(n) {
+		return false
+	}
+	i := last - 1
+	for p := n.Parent; p != nil && i >= 0; p = p.Parent {
+		if c[i].matches(p) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+func (c compoundSelector) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || (c.tag != "" && n.Data != c.tag) {
+		return false
+	}
+	for _, a := range c.attrs {
+		if !a.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrSelector) matches(n *html.Node) bool {
+	value, ok := lookupAttr(n, a.key)
+	if !ok {
+		return false
+	}
+	want := a.value
+	if a.fold {
+		value, want = strings.ToLower(value), strings.ToLower(want)
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == want
+	case "~=":
+		return slices.Contains(strings.Fields(value), want)
+	case "*=":
+		return want != "" && strings.Contains(value, want)
+	case "^=":
+		return want != "" && strings.HasPrefix(value, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(value, want)
+	}
+	return false
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selectorParser) skipSpace() {
+	for !p.done() && isSelectorSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+// ident reads a tag, class, id or attribute name
+func (p *selectorParser) ident() string {
+	start := p.pos
+	for !p.done() {
+		c := p.s[p.pos]
+		if c != '-' && c != '_' && (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var complex complexSelector
+	for {
+		compound, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		complex = append(complex, compound)
+		p.skipSpace()
+		if p.done() || p.s[p.pos] == ',' {
+			return complex, nil
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var c compoundSelector
+	start := p.pos
+	if !p.done() && p.s[p.pos] == '*' {
+		p.pos++
+	} else {
+		c.tag = strings.ToLower(p.ident())
+	}
+
+	for !p.done() {
+		switch p.s[p.pos] {
+		case '.', '#':
+			key, op := "class", "~="
+			if p.s[p.pos] == '#' {
+				key, op = "id", "="
+			}
+			p.pos++
+			name := p.ident()
+			if name == "" {
+				return c, fmt.Errorf("expected a name at offset %d", p.pos)
+			}
+			c.attrs = append(c.attrs, attrSelector{key: key, op: op, value: name})
+		case '[':
+			p.pos++
+			a, err := p.parseAttr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+		default:
+			if p.pos == start {
+				return c, fmt.Errorf("expected a selector at offset %d", p.pos)
+			}
+			return c, nil
+		}
+	}
+	if p.pos == start {
+		return c, fmt.Errorf("expected a selector at offset %d", p.pos)
+	}
+	return c, nil
+}
+
+// parseAttr parses an attribute test after its opening bracket
+func (p *selectorParser) parseAttr() (attrSelector, error) {
+	var a attrSelector
+	p.skipSpace()
+	a.key = strings.ToLower(p.ident())
+	if a.key == "" {
+		return a, fmt.Errorf("expected an attribute name at offset %d", p.pos)
+	}
+	p.skipSpace()
+	if p.done() {
+		return a, fmt.Errorf("unterminated attribute test")
+	}
+	if p.s[p.pos] == ']' {
+		p.pos++
+		return a, nil
+	}
+
+	for _, op := range []string{"=", "~=", "*=", "^=", "$="} {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			a.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if a.op == "" {
+		return a, fmt.Errorf("unknown attribute operator at offset %d", p.pos)
+	}
+
+	p.skipSpace()
+	if !p.done() && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		quote := p.s[p.pos]
+		end := strings.IndexByte(p.s[p.pos+1:], quote)
+		if end == -1 {
+			return a, fmt.Errorf("unterminated string at offset %d", p.pos)
+		}
+		a.value = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		a.value = p.ident()
+		if a.value == "" {
+			return a, fmt.Errorf("expected a value at offset %d", p.pos)
+		}
+	}
+
+	p.skipSpace()
+	if !p.done() && (p.s[p.pos] == 'i' || p.s[p.pos] == 'I') {
+		a.fold = true
+		p.pos++
+		p.skipSpace()
+	}
+	if p.done() || p.s[p.pos] != ']' {
+		return a, fmt.Errorf("expected ] at offset %d", p.pos)
+	}
+	p.pos++
+	return a, nil
+}
+
+func isSelectorSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// findElement returns the first element in the document with the given id
+func findElement(t *testing.T, doc *html.Node, id string) *html.Node {
+	t.Helper()
+	sel, err := parseSelector("#" + id)
+	if err != nil {
+		t.Fatalf("parseSelector() error = %v", err)
+	}
+	n := sel.first(doc)
+	if n == nil {
+		t.Fatalf("no element with id %q", id)
+	}
+	return n
+}
+
+func TestSelectorMatches(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`
+		<table class="menu">
+			<tr><td id="cell" class="MenuItem vegan" data-station="Grill">Eggs</td></tr>
+		</table>
+		<ul><li id="item">Toast</li></ul>`))
+	if err != nil {
+		t.Fatalf("html.Parse() error = %v", err)
+	}
+	cell := findElement(t, doc, "cell")
+	item := findElement(t, doc, "item")
+
+	tests := []struct {
+		selector string
+		node     *html.Node
+		want     bool
+	}{
+		{"td", cell, true},
+		{"TD", cell, true},
+		{"*", cell, true},
+		{"li", cell, false},
+		{".vegan", cell, true},
+		{".Vegan", cell, false},
+		{"td.MenuItem.vegan", cell, true},
+		{"#cell", cell, true},
+		{"[data-station]", cell, true},
+		{"[data-station=Grill]", cell, true},
+		{"[data-station=grill]", cell, false},
+		{"[data-station=grill i]", cell, true},
+		{`[data-station="Grill"]`, cell, true},
+		{"[class~=vegan]", cell, true},
+		{"[class*=item]", cell, false},
+		{"[class*=item i]", cell, true},
+		{"[class^=Menu]", cell, true},
+		{"[class$=vegan]", cell, true},
+		{"table.menu td", cell, true},
+		{"ul td", cell, false},
+		{"table tr td", cell, true},
+		{"div, li", item, true},
+		{"div, span", item, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := parseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("parseSelector() error = %v", err)
+			}
+			if got := sel.matches(tt.node); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	for _, s := range []string{"", "td,", ".", "#", "td > li", "[class", "[class|=x]", "[class=]", `[class="x]`, "[class=x y]"} {
+		if _, err := parseSelector(s); err == nil {
+			t.Errorf("parseSelector(%q) should return error", s)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package planner picks menu items for a day's meals to approach calorie and
+// protein targets. It knows nothing about where menus come from: callers pass
+// the items each hall serves at each meal, with their nutrition.
+package planner
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Bounds on how many items are picked for one meal. The search tries every
+// combination of a hall's items, so the limit keeps it fast.
+const (
+	DefaultMaxItemsPerMeal = 4
+	MaxItemsPerMealLimit   = 6
+)
+
+// itemPenalty is added to a meal's cost per item, so of two equally close
+// choices the one with fewer items wins
+const itemPenalty = 0.01
+
+// Item is a menu item the planner may pick, one serving each
+type Item struct {
+	Name          string  `json:"name"`
+	Location      string  `json:"location"`
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	TotalFat      float64 `json:"totalFat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	// Ingredients and Allergens are checked against dietary restrictions
+	Ingredients string `json:"-"`
+	Allergens   string `json:"-"`
+}
+
+// Meal is one meal of the day with the items every hall serves for it
+type Meal struct {
+	MealType string
+	Items    []Item
+}
+
+// Target is a nutrition goal; zero means no goal for that nutrient
+type Target struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+}
+
+// Totals sum the nutrition of picked items
+type Totals struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	TotalFat      float64 `json:"totalFat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+}
+
+// Request describes a day to plan
+type Request struct {
+	// Target is the goal for the whole day
+	Target Target
+	// Restrictions are dietary restrictions, like "vegetarian" (see Restrictions)
+	Restrictions []string
+	// Avoid lists extra words ruling items out, like "mushroom"
+	Avoid []string
+	// MaxItemsPerMeal bounds the items per meal; zero means DefaultMaxItemsPerMeal
+	MaxItemsPerMeal int
+	// Meals are planned in order
+	Meals []Meal
+}
+
+// MealPlan is the items picked for one meal, all from one hall
+type MealPlan struct {
+	MealType string `json:"mealType"`
+	// Location is the hall the meal is eaten at; empty if nothing fit
+	Location string `json:"location"`
+	Items    []Item `json:"items"`
+	// Target is the share of the day's target left for this meal
+	Target Target `json:"target"`
+	Totals Totals `json:"totals"`
+}
+
+// Plan is a day of meals
+type Plan struct {
+	Meals  []MealPlan `json:"meals"`
+	Target Target     `json:"target"`
+	Totals Totals     `json:"totals"`
+	// Excluded counts items ruled out by dietary restrictions
+	Excluded int `json:"excluded"`
+}
+
+// mealShares weight how much of the day's target a meal gets; other meals
+// get the average weight
+var mealShares = map[string]float64{
+	"breakfast": 0.25,
+	"brunch":    0.45,
+	"lunch":     0.35,
+	"dinner":    0.40,
+}
+
+// Plan picks items for each meal in order. The day's target is split across
+// meals by their share, and each meal's target is what is left of the day's
+// after the meals before it, so a light breakfast makes a bigger lunch. Each
+// meal is eaten at one hall: every hall is searched and the closest pick
+// wins, ties going to the hall listed first. The result depends only on the
+// request, including the order of meals and items.
+func (r Request) Plan() (Plan, error) {
+	if r.Target.Calories < 0 || r.Target.Protein < 0 {
+		return Plan{}, fmt.Errorf("targets must not be negative")
+	}
+	if r.Target.Calories == 0 && r.Target.Protein == 0 {
+		return Plan{}, fmt.Errorf("a calorie or protein target is required")
+	}
+	maxItems := r.MaxItemsPerMeal
+	if maxItems == 0 {
+		maxItems = DefaultMaxItemsPerMeal
+	}
+	if maxItems < 0 || maxItems > MaxItemsPerMealLimit {
+		return Plan{}, fmt.Errorf("max items per meal must be between 1 and %d", MaxItemsPerMealLimit)
+	}
+	f, err := newFilter(r.Restrictions, r.Avoid)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{Meals: []MealPlan{}, Target: r.Target}
+	shares := r.shares()
+	for i, meal := range r.Meals {
+		remaining := 0.0
+		for _, share := range shares[i:] {
+			remaining += share
+		}
+		fraction := shares[i] / remaining
+		target := Target{
+			Calories: math.Max(0, r.Target.Calories-plan.Totals.Calories) * fraction,
+			Protein:  math.Max(0, r.Target.Protein-plan.Totals.Protein) * fraction,
+		}
+
+		// Group allowed items by hall, keeping the order halls first appear in
+		var halls []string
+		byHall := make(map[string][]Item)
+		for _, item := range meal.Items {
+			if !f.allows(item) {
+				plan.Excluded++
+				continue
+			}
+			if _, seen := byHall[item.Location]; !seen {
+				halls = append(halls, item.Location)
+			}
+			byHall[item.Location] = append(byHall[item.Location], item)
+		}
+
+		mealPlan := MealPlan{MealType: meal.MealType, Items: []Item{}, Target: target}
+		best := target.cost(Totals{}, 0)
+		for _, hall := range halls {
+			picked, cost := search(byHall[hall], target, maxItems)
+			if cost < best-1e-9 {
+				best = cost
+				mealPlan.Location = hall
+				mealPlan.Items = picked
+			}
+		}
+		mealPlan.Totals = sum(mealPlan.Items)
+		mealPlan.Target = target.rounded()
+		plan.Meals = append(plan.Meals, mealPlan)
+		plan.Totals = plan.Totals.add(mealPlan.Totals)
+	}
+	for i := range plan.Meals {
+		plan.Meals[i].Totals = plan.Meals[i].Totals.rounded()
+	}
+	plan.Totals = plan.Totals.rounded()
+	return plan, nil
+}
+
+// shares returns each meal's weight
+func (r Request) shares() []float64 {
+	average := 0.0
+	for _, share := range mealShares {
+		average += share
+	}
+	average /= float64(len(mealShares))
+
+	shares := make([]float64, len(r.Meals))
+	for i, meal := range r.Meals {
+		share, ok := mealShares[strings.ToLower(meal.MealType)]
+		if !ok {
+			share = average
+		}
+		shares[i] = share
+	}
+	return shares
+}
+
+// cost scores how far totals are from a target, lower being better: the
+// relative calorie error in either direction plus the relative protein
+// shortfall. Extra protein costs nothing.
+func (t Target) cost(totals Totals, items int) float64 {
+	cost := itemPenalty * float64(items)
+	if t.Calories > 0 {
+		cost += math.Abs(totals.Calories-t.Calories) / t.Calories
+	}
+	if t.Protein > 0 && totals.Protein < t.Protein {
+		cost += (t.Protein - totals.Protein) / t.Protein
+	}
+	return cost
+}
+
+// search finds the subset of at most maxItems items with the lowest cost.
+// Items are tried in order and a later subset must be strictly better to
+// replace an earlier one, so ties keep the earlier items.
+func search(items []Item, target Target, maxItems int) ([]Item, float64) {
+	best := []Item{}
+	bestCost := target.cost(Totals{}, 0)
+
+	picked := make([]Item, 0, maxItems)
+	var visit func(start int, totals Totals)
+	visit = func(start int, totals Totals) {
+		if len(picked) == maxItems {
+			return
+		}
+		for i := start; i < len(items); i++ {
+			next := totals.add(sum(items[i : i+1]))
+			picked = append(picked, items[i])
+			n := len(picked)
+			if cost := target.cost(next, n); cost < bestCost-1e-9 {
+				best, bestCost = append([]Item{}, picked...), cost
+			}
+			// Items only add calories, so once over the calorie target the
+			// overshoot alone bounds every larger subset's cost; without a
+			// calorie target, more items only cost more once protein is met
+			if target.Calories > 0 {
+				if (next.Calories-target.Calories)/target.Calories+itemPenalty*float64(n) < bestCost {
+					visit(i+1, next)
+				}
+			} else if next.Protein < target.Protein {
+				visit(i+1, next)
+			}
+			picked = picked[:n-1]
+		}
+	}
+	visit(0, Totals{})
+	return best, bestCost
+}
+
+// sum totals the nutrition of items
+func sum(items []Item) Totals {
+	var t Totals
+	for _, item := range items {
+		t.Calories += item.Calories
+		t.Protein += item.Protein
+		t.TotalFat += item.TotalFat
+		t.Carbohydrates += item.Carbohydrates
+	}
+	return t
+}
+
+func (t Totals) add(other Totals) Totals {
+	return Totals{
+		Calories:      t.Calories + other.Calories,
+		Protein:       t.Protein + other.Protein,
+		TotalFat:      t.TotalFat + other.TotalFat,
+		Carbohydrates: t.Carbohydrates + other.Carbohydrates,
+	}
+}
+
+// rounded rounds totals to tenths, hiding floating point noise
+func (t Totals) rounded() Totals {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return Totals{
+		Calories:      round(t.Calories),
+		Protein:       round(t.Protein),
+		TotalFat:      round(t.TotalFat),
+		Carbohydrates: round(t.Carbohydrates),
+	}
+}
+
+// rounded rounds a target to whole calories and tenths of a gram
+func (t Target) rounded() Target {
+	return Target{
+		Calories: math.Round(t.Calories),
+		Protein:  math.Round(t.Protein*10) / 10,
+	}
+}
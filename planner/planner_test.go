@@ -0,0 +1,205 @@
+package planner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// names returns the names of picked items
+func names(items []Item) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+	return result
+}
+
+func TestPlanSingleMeal(t *testing.T) {
+	plan, err := Request{
+		Target: Target{Calories: 600},
+		Meals: []Meal{{MealType: "Lunch", Items: []Item{
+			{Name: "Soup", Location: "Branner", Calories: 300},
+			{Name: "Salad", Location: "Branner", Calories: 300},
+			{Name: "Burrito", Location: "Branner", Calories: 600},
+		}}},
+	}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// Two items hit the target as well as one, so the single item wins
+	if got := names(plan.Meals[0].Items); !reflect.DeepEqual(got, []string{"Burrito"}) {
+		t.Errorf("Items = %v, want [Burrito]", got)
+	}
+	if plan.Totals.Calories != 600 || plan.Meals[0].Target.Calories != 600 {
+		t.Errorf("Totals = %+v, Target = %+v", plan.Totals, plan.Meals[0].Target)
+	}
+}
+
+func TestPlanProteinTarget(t *testing.T) {
+	plan, err := Request{
+		Target: Target{Protein: 40},
+		Meals: []Meal{{MealType: "Dinner", Items: []Item{
+			{Name: "Rice", Location: "Wilbur", Calories: 200, Protein: 4},
+			{Name: "Tofu", Location: "Wilbur", Calories: 180, Protein: 20},
+			{Name: "Chicken", Location: "Wilbur", Calories: 250, Protein: 30},
+			{Name: "Lentils", Location: "Wilbur", Calories: 230, Protein: 18},
+		}}},
+	}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// The fewest items reaching the target, first in menu order
+	if got := names(plan.Meals[0].Items); !reflect.DeepEqual(got, []string{"Tofu", "Chicken"}) {
+		t.Errorf("Items = %v, want [Tofu Chicken]", got)
+	}
+	if plan.Meals[0].Totals.Protein != 50 {
+		t.Errorf("Totals = %+v", plan.Meals[0].Totals)
+	}
+}
+
+func TestPlanPicksOneHallPerMeal(t *testing.T) {
+	items := []Item{
+		{Name: "Bagel", Location: "Arrillaga", Calories: 350, Protein: 10},
+		{Name: "Omelette", Location: "Branner", Calories: 400, Protein: 25},
+		{Name: "Toast", Location: "Arrillaga", Calories: 150, Protein: 5},
+		{Name: "Oatmeal", Location: "Wilbur", Calories: 400, Protein: 25},
+	}
+	plan, err := Request{
+		Target: Target{Calories: 400, Protein: 25},
+		Meals:  []Meal{{MealType: "Breakfast", Items: items}},
+	}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	meal := plan.Meals[0]
+	// Branner and Wilbur tie; the hall listed first wins
+	if meal.Location != "Branner" || !reflect.DeepEqual(names(meal.Items), []string{"Omelette"}) {
+		t.Errorf("Breakfast = %s %v, want Branner [Omelette]", meal.Location, names(meal.Items))
+	}
+	for _, item := range meal.Items {
+		if item.Location != meal.Location {
+			t.Errorf("item %s from %s in a meal at %s", item.Name, item.Location, meal.Location)
+		}
+	}
+}
+
+func TestPlanCarriesShortfallForward(t *testing.T) {
+	meals := []Meal{
+		{MealType: "Breakfast"}, // the hall is closed
+		{MealType: "Lunch", Items: []Item{{Name: "Pasta", Location: "Lakeside", Calories: 700, Protein: 20}}},
+		{MealType: "Dinner", Items: []Item{{Name: "Curry", Location: "Lakeside", Calories: 800, Protein: 30}}},
+	}
+	plan, err := Request{Target: Target{Calories: 2000, Protein: 100}, Meals: meals}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Meals) != 3 {
+		t.Fatalf("Meals = %+v, want 3", plan.Meals)
+	}
+	breakfast, lunch, dinner := plan.Meals[0], plan.Meals[1], plan.Meals[2]
+	if breakfast.Location != "" || len(breakfast.Items) != 0 || breakfast.Target.Calories != 500 {
+		t.Errorf("Breakfast = %+v, want no items and a quarter of the target", breakfast)
+	}
+	// Lunch gets its share of the whole day, since breakfast ate nothing
+	if lunch.Target.Calories != 933 {
+		t.Errorf("Lunch target = %+v, want 2000*0.35/0.75", lunch.Target)
+	}
+	// Dinner gets whatever is left after lunch
+	if dinner.Target.Calories != 1300 || dinner.Target.Protein != 80 {
+		t.Errorf("Dinner target = %+v, want the remaining 1300 cal and 80 g", dinner.Target)
+	}
+	if plan.Totals.Calories != 1500 || plan.Totals.Protein != 50 {
+		t.Errorf("Totals = %+v", plan.Totals)
+	}
+}
+
+func TestPlanRestrictions(t *testing.T) {
+	items := []Item{
+		{Name: "Pepperoni Pizza", Location: "Wilbur", Calories: 600, Protein: 25, Allergens: "wheat, milk"},
+		{Name: "Veggie Stir Fry", Location: "Wilbur", Calories: 450, Protein: 15, Ingredients: "tofu, broccoli, soy sauce"},
+		{Name: "Grilled Salmon", Location: "Wilbur", Calories: 500, Protein: 35},
+		{Name: "Mushroom Risotto", Location: "Wilbur", Calories: 550, Protein: 12, Allergens: "milk"},
+	}
+
+	plan, err := Request{
+		Target:       Target{Calories: 500},
+		Restrictions: []string{"Vegetarian"},
+		Avoid:        []string{"mushroom"},
+		Meals:        []Meal{{MealType: "Dinner", Items: items}},
+	}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if got := names(plan.Meals[0].Items); !reflect.DeepEqual(got, []string{"Veggie Stir Fry"}) {
+		t.Errorf("Items = %v, want the only vegetarian item without mushrooms", got)
+	}
+	if plan.Excluded != 3 {
+		t.Errorf("Excluded = %d, want 3", plan.Excluded)
+	}
+
+	plan, err = Request{
+		Target:       Target{Calories: 500},
+		Restrictions: []string{"vegan", "soy free"},
+		Meals:        []Meal{{MealType: "Dinner", Items: items}},
+	}.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Meals[0].Items) != 0 || plan.Excluded != 4 {
+		t.Errorf("Plan() = %+v, want every item excluded", plan)
+	}
+}
+
+func TestPlanIsDeterministic(t *testing.T) {
+	var items []Item
+	for i, name := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"} {
+		for _, hall := range []string{"North", "South"} {
+			items = append(items, Item{
+				Name:     name,
+				Location: hall,
+				Calories: float64(100 + 37*i),
+				Protein:  float64(3 + 2*i),
+			})
+		}
+	}
+	req := Request{
+		Target: Target{Calories: 2200, Protein: 90},
+		Meals:  []Meal{{"Breakfast", items}, {"Lunch", items}, {"Dinner", items}},
+	}
+	first, err := req.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	for range 5 {
+		if again, _ := req.Plan(); !reflect.DeepEqual(first, again) {
+			t.Fatalf("Plan() is not deterministic:\n%+v\n%+v", first, again)
+		}
+	}
+	for _, meal := range first.Meals {
+		if len(meal.Items) == 0 || len(meal.Items) > DefaultMaxItemsPerMeal {
+			t.Errorf("%s has %d items", meal.MealType, len(meal.Items))
+		}
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{"no target", Request{}, "target is required"},
+		{"negative target", Request{Target: Target{Calories: -1}}, "negative"},
+		{"too many items", Request{Target: Target{Calories: 2000}, MaxItemsPerMeal: 20}, "max items"},
+		{"unknown restriction", Request{Target: Target{Calories: 2000}, Restrictions: []string{"carnivore"}}, "unknown dietary restriction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.req.Plan()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Plan() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package planner
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Word lists shared by several restrictions
+var (
+	meat      = []string{"beef", "steak", "brisket", "veal", "lamb", "chicken", "turkey", "duck", "meat", "meatball", "gelatin"}
+	pork      = []string{"pork", "bacon", "ham", "sausage", "pepperoni", "salami", "prosciutto", "chorizo", "carnitas", "lard"}
+	fish      = []string{"fish", "salmon", "tuna", "cod", "tilapia", "halibut", "trout", "anchovy", "anchovies"}
+	shellfish = []string{"shellfish", "shrimp", "prawn", "crab", "lobster", "clam", "mussel", "oyster", "scallop"}
+	dairy     = []string{"milk", "dairy", "cheese", "butter", "cream", "yogurt", "whey", "casein", "ghee", "lactose"}
+	egg       = []string{"egg", "mayonnaise", "meringue"}
+)
+
+// restrictions map each dietary restriction to the words that rule an item
+// out when they appear in its name, ingredients or allergens. Matching is
+// conservative: "peanut butter" fails dairy-free, since a menu can't say
+// which butter it means.
+var restrictions = map[string][]string{
+	"vegetarian":     concat(meat, pork, fish, shellfish),
+	"vegan":          concat(meat, pork, fish, shellfish, dairy, egg, []string{"honey"}),
+	"pescatarian":    concat(meat, pork),
+	"pork-free":      pork,
+	"gluten-free":    {"wheat", "gluten", "barley", "rye", "malt", "couscous", "seitan", "farro", "bulgur", "semolina"},
+	"dairy-free":     dairy,
+	"egg-free":       egg,
+	"nut-free":       {"nut", "peanut", "almond", "walnut", "pecan", "cashew", "pistachio", "hazelnut", "macadamia"},
+	"peanut-free":    {"peanut"},
+	"fish-free":      fish,
+	"shellfish-free": shellfish,
+	"soy-free":       {"soy", "soybean", "tofu", "edamame", "tempeh", "miso"},
+}
+
+func concat(lists ...[]string) []string {
+	var words []string
+	for _, list := range lists {
+		words = append(words, list...)
+	}
+	return words
+}
+
+// Restrictions returns the dietary restrictions the planner understands, sorted
+func Restrictions() []string {
+	return slices.Sorted(maps.Keys(restrictions))
+}
+
+// NormalizeRestriction returns a restriction's canonical name, accepting
+// spellings like "Gluten Free" or "gluten_free"
+func NormalizeRestriction(name string) (string, error) {
+	canonical := strings.ToLower(strings.TrimSpace(name))
+	canonical = strings.NewReplacer(" ", "-", "_", "-").Replace(canonical)
+	if _, ok := restrictions[canonical]; !ok {
+		return "", fmt.Errorf("unknown dietary restriction %q (known: %s)", name, strings.Join(Restrictions(), ", "))
+	}
+	return canonical, nil
+}
+
+// filter rejects items whose name, ingredients or allergens mention a word
+type filter struct {
+	pattern *regexp.Regexp
+}
+
+// newFilter builds a filter from restrictions and extra words to avoid.
+// Words match whole words, singular or plural, ignoring case.
+func newFilter(restrictionNames, avoid []string) (filter, error) {
+	var words []string
+	for _, name := range restrictionNames {
+		canonical, err := NormalizeRestriction(name)
+		if err != nil {
+			return filter{}, err
+		}
+		words = append(words, restrictions[canonical]...)
+	}
+	for _, word := range avoid {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return filter{}, nil
+	}
+
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(strings.ToLower(word))
+	}
+	return filter{regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)(?:s|es)?\b`)}, nil
+}
+
+// allows reports whether an item passes the filter
+func (f filter) allows(item Item) bool {
+	if f.pattern == nil {
+		return true
+	}
+	return !f.pattern.MatchString(item.Name + "\n" + item.Ingredients + "\n" + item.Allergens)
+}
@@ -0,0 +1,61 @@
+package planner
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeRestriction(t *testing.T) {
+	for input, want := range map[string]string{
+		"vegan":        "vegan",
+		"Gluten Free":  "gluten-free",
+		" dairy_free ": "dairy-free",
+	} {
+		got, err := NormalizeRestriction(input)
+		if err != nil || got != want {
+			t.Errorf("NormalizeRestriction(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizeRestriction("keto"); err == nil {
+		t.Error("NormalizeRestriction() should reject unknown restrictions")
+	}
+	if !slices.IsSorted(Restrictions()) || !slices.Contains(Restrictions(), "vegetarian") {
+		t.Errorf("Restrictions() = %v", Restrictions())
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		restriction string
+		item        Item
+		allowed     bool
+	}{
+		{"vegetarian", Item{Name: "Ham and Cheese Sandwich"}, false},
+		{"vegetarian", Item{Name: "Graham Crackers"}, true},
+		{"vegetarian", Item{Name: "Roasted Eggplant"}, true},
+		{"vegan", Item{Name: "Roasted Eggplant"}, true},
+		{"vegan", Item{Name: "Frittata", Ingredients: "eggs, spinach"}, false},
+		{"gluten-free", Item{Name: "Muffin", Allergens: "Wheat, Milk"}, false},
+		{"nut-free", Item{Name: "Granola", Allergens: "tree nuts"}, false},
+		{"nut-free", Item{Name: "Coconut Rice"}, true},
+		{"shellfish-free", Item{Name: "Fish Tacos"}, true},
+		{"fish-free", Item{Name: "Shellfish Stew"}, true},
+	}
+	for _, tt := range tests {
+		f, err := newFilter([]string{tt.restriction}, nil)
+		if err != nil {
+			t.Fatalf("newFilter(%q) error = %v", tt.restriction, err)
+		}
+		if got := f.allows(tt.item); got != tt.allowed {
+			t.Errorf("%s allows %q = %v, want %v", tt.restriction, tt.item.Name, got, tt.allowed)
+		}
+	}
+
+	f, _ := newFilter(nil, []string{"cilantro", " "})
+	if f.allows(Item{Name: "Salsa", Ingredients: "tomato, Cilantro, onion"}) || !f.allows(Item{Name: "Rice"}) {
+		t.Error("avoided words should exclude items mentioning them")
+	}
+	if f, _ := newFilter(nil, nil); !f.allows(Item{Name: "Anything"}) {
+		t.Error("an empty filter should allow every item")
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dayMealTypes returns the meals considered when planning a day. Brunch is
+// only served on weekends, so it isn't fetched on weekdays.
+func dayMealTypes(day time.Time) []string {
+	candidates := []string{"Breakfast", "Lunch", "Dinner"}
+	if weekday := day.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		candidates = []string{"Breakfast", "Brunch", "Lunch", "Dinner"}
+	}
+	var mealTypes []string
+	for _, m := range candidates {
+		if config.IsValidMealType(m) {
+			mealTypes = append(mealTypes, m)
+		}
+	}
+	return mealTypes
+}
+
+// locationArgument describes the location prompt argument using the config enum
+func locationArgument(required bool) *mcp.PromptArgument {
+	return &mcp.PromptArgument{
+		Name:        "location",
+		Title:       "Dining Hall",
+		Description: "Dining hall location. One of: " + strings.Join(config.ValidLocations, ", "),
+		Required:    required,
+	}
+}
+
+// mealTypeArgument describes the mealType prompt argument using the config enum
+func mealTypeArgument(required bool) *mcp.PromptArgument {
+	return &mcp.PromptArgument{
+		Name:        "mealType",
+		Title:       "Meal",
+		Description: "Meal type. One of: " + strings.Join(config.ValidMealTypes, ", "),
+		Required:    required,
+	}
+}
+
+// dateArgument describes an optional date prompt argument
+func dateArgument(name string) *mcp.PromptArgument {
+	return &mcp.PromptArgument{
+		Name:        name,
+		Title:       "Date",
+		Description: "Date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like \"tomorrow\". If not provided, uses today's date",
+	}
+}
+
+// promptDate resolves an optional date prompt argument, defaulting to today
+func promptDate(value string) (time.Time, error) {
+	if value == "" {
+		return dates.Now(), nil
+	}
+	return dates.Resolve(value, dates.Now())
+}
+
+// promptLocations resolves an optional location argument, defaulting to all locations
+func promptLocations(location string) ([]string, error) {
+	if location == "" {
+		return config.ValidLocations, nil
+	}
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	return []string{location}, nil
+}
+
+// menuSection is a menu a prompt embeds, under a heading
+type menuSection struct {
+	heading, location, date, mealType string
+}
+
+// writeMenuSections fetches menus in order and appends them to b. It reports
+// whether any menu had items, and stops early with ctx's error when ctx is
+// done. Fetches run one at a time since the Stanford client serializes them
+// on its session anyway.
+func writeMenuSections(ctx context.Context, b *strings.Builder, sections []menuSection) (bool, error) {
+	hasItems := false
+	for _, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if writeMenuSection(b, s.heading, s.location, s.date, s.mealType) {
+			hasItems = true
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return hasItems, nil
+}
+
+// menusHeader introduces the menus of locations, naming the hall when there is one
+func menusHeader(locations []string, meal, date string) string {
+	if len(locations) == 1 {
+		return fmt.Sprintf("Here are the %s %smenus for %s.\n\n", locations[0], meal, date)
+	}
+	return fmt.Sprintf("Here are the dining hall %smenus for %s.\n\n", meal, date)
+}
+
+// writeMenuSection fetches a menu and appends it to b as a Markdown section.
+// It reports whether the menu had any items.
+func writeMenuSection(b *strings.Builder, heading, location, date, mealType string) bool {
+	items, err := menuProviders.GetMenu(location, date, mealType)
+	if err != nil {
+		fmt.Fprintf(b, "### %s\n(menu unavailable: %v)\n\n", heading, err)
+		return false
+	}
+	if len(items) == 0 {
+		return false
+	}
+	fmt.Fprintf(b, "### %s\n", heading)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+	b.WriteString("\n")
+	return true
+}
+
+// userPrompt wraps text as a single user message prompt result
+func userPrompt(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: text}},
+		},
+	}
+}
+
+// PlanMyDay builds the plan_my_day prompt with the day's menus embedded
+func PlanMyDay(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	locations, err := promptLocations(args["location"])
+	if err != nil {
+		return nil, err
+	}
+	day, err := promptDate(args["date"])
+	if err != nil {
+		return nil, err
+	}
+	if err := initProviders(); err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	date := utils.FormatDate(day)
+	var sections []menuSection
+	for _, location := range locations {
+		for _, mealType := range dayMealTypes(day) {
+			sections = append(sections, menuSection{location + " - " + mealType, location, date, mealType})
+		}
+	}
+	var b strings.Builder
+	b.WriteString(menusHeader(locations, "", date))
+	found, err := writeMenuSections(ctx, &b, sections)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		b.WriteString("No menus were published for this day.\n\n")
+	}
+	b.WriteString("Plan my meals for the day: pick one breakfast (or brunch), one lunch and one dinner " +
+		"from the menus above, say which dining hall each comes from, and briefly explain the picks.")
+	if prefs := args["preferences"]; prefs != "" {
+		fmt.Fprintf(&b, " Take these preferences into account: %s.", prefs)
+	}
+
+	return userPrompt("Meal plan for "+date, b.String()), nil
+}
+
+// AllergySafeDinner builds the allergy_safe_dinner prompt with dinner menus embedded
+func AllergySafeDinner(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	allergies := strings.TrimSpace(args["allergies"])
+	if allergies == "" {
+		return nil, fmt.Errorf("allergies is required")
+	}
+	locations, err := promptLocations(args["location"])
+	if err != nil {
+		return nil, err
+	}
+	day, err := promptDate(args["date"])
+	if err != nil {
+		return nil, err
+	}
+	if err := initProviders(); err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	date := utils.FormatDate(day)
+	var sections []menuSection
+	for _, location := range locations {
+		sections = append(sections, menuSection{location, location, date, "Dinner"})
+	}
+	var b strings.Builder
+	b.WriteString(menusHeader(locations, "dinner ", date))
+	found, err := writeMenuSections(ctx, &b, sections)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		b.WriteString("No dinner menus were published for this day.\n\n")
+	}
+	fmt.Fprintf(&b, "I am allergic to: %s. Suggest a dinner from the menus above that avoids these allergens. "+
+		"The menus only list dish names, so flag any dish whose ingredients are uncertain and "+
+		"remind me to confirm allergen information with dining hall staff.", allergies)
+
+	return userPrompt("Allergy-safe dinner for "+date, b.String()), nil
+}
+
+// WeeklyMealPlan builds the weekly_meal_plan prompt with a week of menus embedded
+func WeeklyMealPlan(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	location, err := config.NormalizeLocation(args["location"])
+	if err != nil {
+		return nil, err
+	}
+	mealTypes := []string{"Breakfast", "Lunch", "Dinner"}
+	if args["mealType"] != "" {
+		mealType, err := config.NormalizeMealType(args["mealType"])
+		if err != nil {
+			return nil, err
+		}
+		mealTypes = []string{mealType}
+	}
+	start, err := promptDate(args["startDate"])
+	if err != nil {
+		return nil, err
+	}
+	if err := initProviders(); err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	var sections []menuSection
+	for i := 0; i < 7; i++ {
+		date := utils.FormatDate(start.AddDate(0, 0, i))
+		for _, mealType := range mealTypes {
+			sections = append(sections, menuSection{date + " - " + mealType, location, date, mealType})
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Here are the %s menus for the week starting %s.\n\n", location, utils.FormatDate(start))
+	if _, err := writeMenuSections(ctx, &b, sections); err != nil {
+		return nil, err
+	}
+	b.WriteString("Build me a balanced weekly meal plan from the menus above, choosing one dish per meal " +
+		"and avoiding repeating the same dish on consecutive days.")
+	if goals := args["goals"]; goals != "" {
+		fmt.Fprintf(&b, " My goals: %s.", goals)
+	}
+
+	return userPrompt("Weekly meal plan for "+location, b.String()), nil
+}
+
+// addPrompts registers the dining prompts on the server
+func addPrompts(server *mcp.Server) {
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "plan_my_day",
+		Title:       "Plan My Day",
+		Description: "Pick breakfast, lunch and dinner for a date using live dining hall menus",
+		Arguments: []*mcp.PromptArgument{
+			locationArgument(false),
+			dateArgument("date"),
+			{Name: "preferences", Title: "Preferences", Description: "Optional dietary preferences (e.g., vegetarian, high protein)"},
+		},
+	}, PlanMyDay)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "allergy_safe_dinner",
+		Title:       "Allergy-Safe Dinner",
+		Description: "Find a dinner that avoids the given allergens using live dining hall menus",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "allergies", Title: "Allergies", Description: "Comma-separated allergens to avoid (e.g., peanuts, shellfish)", Required: true},
+			locationArgument(false),
+			dateArgument("date"),
+		},
+	}, AllergySafeDinner)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "weekly_meal_plan",
+		Title:       "Weekly Meal Plan",
+		Description: "Plan a week of meals at one dining hall using live menus",
+		Arguments: []*mcp.PromptArgument{
+			locationArgument(true),
+			mealTypeArgument(false),
+			dateArgument("startDate"),
+			{Name: "goals", Title: "Goals", Description: "Optional goals for the plan (e.g., more vegetables, budget for dessert)"},
+		},
+	}, WeeklyMealPlan)
+}
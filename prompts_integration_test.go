@@ -0,0 +1,104 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/provider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// recordingProvider serves one dish at Branner Dining and records the meal
+// types requested
+type recordingProvider struct {
+	mu        sync.Mutex
+	mealTypes []string
+}
+
+func (p *recordingProvider) Name() string        { return "recording" }
+func (p *recordingProvider) Locations() []string { return []string{"Branner Dining"} }
+func (p *recordingProvider) MealTypes() []string {
+	return []string{"Breakfast", "Brunch", "Lunch", "Dinner"}
+}
+
+func (p *recordingProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mealTypes = append(p.mealTypes, mealType)
+	return []string{mealType + " Special"}, nil
+}
+
+// useRecordingProvider serves menus from a recordingProvider for the test
+func useRecordingProvider(t *testing.T) *recordingProvider {
+	t.Helper()
+	recorder := &recordingProvider{}
+	registry := provider.NewRegistry()
+	registry.Register(recorder)
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+	return recorder
+}
+
+// promptText gets a prompt's text by calling its handler directly
+func promptText(t *testing.T, ctx context.Context, handler mcp.PromptHandler, args map[string]string) (string, error) {
+	t.Helper()
+	result, err := handler(ctx, &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: args}})
+	if err != nil {
+		return "", err
+	}
+	return result.Messages[0].Content.(*mcp.TextContent).Text, nil
+}
+
+// TestPlanMyDayMeals tests that brunch is only fetched on weekends and that
+// menus appear in order
+func TestPlanMyDayMeals(t *testing.T) {
+	recorder := useRecordingProvider(t)
+
+	// 1/15/2025 is a Wednesday
+	text, err := promptText(t, context.Background(), PlanMyDay, map[string]string{"location": "branner", "date": "1/15/2025"})
+	if err != nil {
+		t.Fatalf("PlanMyDay() error = %v", err)
+	}
+	if slices.Contains(recorder.mealTypes, "Brunch") {
+		t.Errorf("requested %v on a weekday, want no brunch", recorder.mealTypes)
+	}
+	if !strings.HasPrefix(text, "Here are the Branner Dining menus for 1/15/2025.") || strings.Contains(text, "Stanford") {
+		t.Errorf("header of %q, want it to name the hall", text)
+	}
+	breakfast, dinner := strings.Index(text, "Breakfast Special"), strings.Index(text, "Dinner Special")
+	if breakfast < 0 || dinner < breakfast {
+		t.Errorf("text = %q, want breakfast before dinner", text)
+	}
+
+	// 1/18/2025 is a Saturday
+	recorder.mealTypes = nil
+	if _, err := promptText(t, context.Background(), PlanMyDay, map[string]string{"location": "branner", "date": "1/18/2025"}); err != nil {
+		t.Fatalf("PlanMyDay() error = %v", err)
+	}
+	if !slices.Contains(recorder.mealTypes, "Brunch") {
+		t.Errorf("requested %v on a Saturday, want brunch", recorder.mealTypes)
+	}
+}
+
+// TestPromptCancelled tests that a cancelled prompt stops fetching menus
+func TestPromptCancelled(t *testing.T) {
+	recorder := useRecordingProvider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if _, err := promptText(t, ctx, AllergySafeDinner, map[string]string{"allergies": "peanuts"}); err == nil {
+		t.Error("AllergySafeDinner() with a cancelled context succeeded")
+	}
+	if len(recorder.mealTypes) != 0 {
+		t.Errorf("requested %v after cancelling, want nothing", recorder.mealTypes)
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/parser"
+)
+
+// Provider is a source of dining hall menus, such as one university's menu site
+type Provider interface {
+	// Name identifies the provider in logs and config
+	Name() string
+	// Locations lists the dining hall display names the provider serves
+	Locations() []string
+	// MealTypes lists the meal types the provider serves
+	MealTypes() []string
+	// GetMenu returns the items served at a location on a date (M/D/YYYY) for a meal type
+	GetMenu(location, date, mealType string) ([]string, error)
+}
+
+// DateLister is implemented by providers that know which dates have menus
+type DateLister interface {
+	AvailableDates(location string) ([]string, error)
+}
+
+// Refresher is implemented by providers whose menus can change and be re-fetched
+// bypassing any cache, reporting whether the menu changed
+type Refresher interface {
+	RefreshMenu(location, date, mealType string) ([]string, bool, error)
+}
+
+// ItemLister is implemented by providers that know more about menu items
+// than their names, such as ingredients, allergens and nutrition labels
+type ItemLister interface {
+	// MenuItems returns a menu's items in menu order
+	MenuItems(location, date, mealType string) ([]parser.Item, error)
+	// ItemDetails returns one item of a menu, matched by name ignoring case
+	ItemDetails(location, date, mealType, item string) (parser.Item, error)
+}
+
+// NutritionFiller is implemented by providers that read nutrition labels
+// separately from menus, so callers can look up a whole menu's labels at once
+type NutritionFiller interface {
+	// FillNutrition sets the nutrition of items that have none, fetching at
+	// most limit labels and stopping when ctx is done. It returns how many
+	// labels were fetched and how many items were skipped.
+	FillNutrition(ctx context.Context, items []parser.Item, limit int) (fetched, skipped int)
+}
+
+// Factory creates a provider from its config entry
+type Factory func(settings config.ProviderSettings) (Provider, error)
+
+// factories maps provider types usable in the config file to their constructors
+var factories = map[string]Factory{
+	"static": func(settings config.ProviderSettings) (Provider, error) {
+		return LoadStatic(settings.Path)
+	},
+}
+
+// RegisterType makes a provider type available to the config file
+func RegisterType(typeName string, factory Factory) {
+	factories[typeName] = factory
+}
+
+// Types returns the registered provider types, sorted
+func Types() []string {
+	types := make([]string, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New creates a provider from its config entry
+func New(settings config.ProviderSettings) (Provider, error) {
+	factory, ok := factories[settings.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider type %q (available: %v)", settings.Type, Types())
+	}
+	return factory(settings)
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/parser"
+)
+
+// registered is a provider with the meal types it offered when registered
+type registered struct {
+	Provider
+	mealTypes []string
+}
+
+// Registry routes menu requests to the provider serving each location
+type Registry struct {
+	providers  []*registered
+	byLocation map[string]*registered
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{byLocation: make(map[string]*registered)}
+}
+
+// Register adds a provider and merges its locations and meal types into the
+// config lists, so tool schemas, completion and the watchlist include them.
+// A location already served by an earlier provider stays with that provider;
+// the names skipped this way are returned. Register is not safe to call while
+// other goroutines read the config, so register providers before serving.
+func (r *Registry) Register(p Provider) []string {
+	entry := &registered{Provider: p, mealTypes: slices.Clone(p.MealTypes())}
+
+	var skipped, added []string
+	for _, location := range p.Locations() {
+		if _, taken := r.byLocation[location]; taken {
+			skipped = append(skipped, location)
+			continue
+		}
+		r.byLocation[location] = entry
+		if !config.IsValidLocation(location) {
+			added = append(added, location)
+		}
+	}
+	r.providers = append(r.providers, entry)
+
+	locations := make([]config.Location, len(added))
+	for i, name := range added {
+		locations[i] = config.Location{Name: name, Value: name}
+	}
+	config.MergeDiscovered(locations, entry.mealTypes)
+
+	return skipped
+}
+
+// Providers returns the registered providers in registration order
+func (r *Registry) Providers() []Provider {
+	providers := make([]Provider, len(r.providers))
+	for i, entry := range r.providers {
+		providers[i] = entry.Provider
+	}
+	return providers
+}
+
+// Lookup returns the provider serving a location, resolving nicknames first,
+// along with the location's canonical name
+func (r *Registry) Lookup(location string) (Provider, string, error) {
+	entry, location, err := r.lookup(location)
+	if err != nil {
+		return nil, "", err
+	}
+	return entry.Provider, location, nil
+}
+
+func (r *Registry) lookup(location string) (*registered, string, error) {
+	location, err := config.NormalizeLocation(location)
+	if err != nil {
+		return nil, "", err
+	}
+	entry, ok := r.byLocation[location]
+	if !ok {
+		return nil, "", fmt.Errorf("no provider serves %s", location)
+	}
+	return entry, location, nil
+}
+
+// GetMenu fetches a menu from the provider serving the location. A meal type
+// the provider doesn't serve yields an empty menu, like a hall that skips brunch.
+func (r *Registry) GetMenu(location, date, mealType string) ([]string, error) {
+	entry, location, err := r.lookup(location)
+	if err != nil {
+		return nil, err
+	}
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(entry.mealTypes, mealType) {
+		return []string{}, nil
+	}
+	return entry.GetMenu(location, date, mealType)
+}
+
+// MenuItems fetches a menu's items from the provider serving the location.
+// Providers that only know item names yield items with just a name.
+func (r *Registry) MenuItems(location, date, mealType string) ([]parser.Item, error) {
+	entry, location, err := r.lookup(location)
+	if err != nil {
+		return nil, err
+	}
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(entry.mealTypes, mealType) {
+		return []parser.Item{}, nil
+	}
+	if lister, ok := entry.Provider.(ItemLister); ok {
+		return lister.MenuItems(location, date, mealType)
+	}
+
+	names, err := entry.GetMenu(location, date, mealType)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]parser.Item, len(names))
+	for i, name := range names {
+		items[i] = parser.Item{Name: name}
+	}
+	return items, nil
+}
+
+// ItemDetails returns one item of a menu, matched by name ignoring case
+func (r *Registry) ItemDetails(location, date, mealType, item string) (parser.Item, error) {
+	entry, location, err := r.lookup(location)
+	if err != nil {
+		return parser.Item{}, err
+	}
+	mealType, err = config.NormalizeMealType(mealType)
+	if err != nil {
+		return parser.Item{}, err
+	}
+	if lister, ok := entry.Provider.(ItemLister); ok && slices.Contains(entry.mealTypes, mealType) {
+		return lister.ItemDetails(location, date, mealType, item)
+	}
+
+	items, err := r.MenuItems(location, date, mealType)
+	if err != nil {
+		return parser.Item{}, err
+	}
+	found, ok := parser.FindItem(items, item)
+	if !ok {
+		return parser.Item{}, fmt.Errorf("%q is not on the %s menu at %s on %s", item, mealType, location, date)
+	}
+	return found, nil
+}
+
+// FillNutrition looks up the nutrition labels of a menu's items from the
+// provider serving the location, like NutritionFiller. Providers that don't
+// read labels separately leave the items as they are.
+func (r *Registry) FillNutrition(ctx context.Context, location string, items []parser.Item, limit int) (fetched, skipped int, err error) {
+	p, _, err := r.Lookup(location)
+	if err != nil {
+		return 0, 0, err
+	}
+	if filler, ok := p.(NutritionFiller); ok {
+		fetched, skipped = filler.FillNutrition(ctx, items, limit)
+	}
+	return fetched, skipped, nil
+}
+
+// AvailableDates lists the dates with menus at a location, if its provider knows them
+func (r *Registry) AvailableDates(location string) ([]string, error) {
+	p, location, err := r.Lookup(location)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := p.(DateLister)
+	if !ok {
+		return nil, fmt.Errorf("%s does not list available dates", p.Name())
+	}
+	return lister.AvailableDates(location)
+}
+
+// RefreshMenu re-fetches a menu bypassing caches and reports whether it changed.
+// Menus from providers that can't refresh are fetched normally and never reported as changed.
+func (r *Registry) RefreshMenu(location, date, mealType string) ([]string, bool, error) {
+	p, location, err := r.Lookup(location)
+	if err != nil {
+		return nil, false, err
+	}
+	refresher, ok := p.(Refresher)
+	if !ok {
+		items, err := r.GetMenu(location, date, mealType)
+		return items, false, err
+	}
+	return refresher.RefreshMenu(location, date, mealType)
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/parser"
+)
+
+// fakeProvider serves fixed items for every menu and counts refreshes
+type fakeProvider struct {
+	name      string
+	locations []string
+	mealTypes []string
+	items     []string
+	refreshes int
+}
+
+func (f *fakeProvider) Name() string        { return f.name }
+func (f *fakeProvider) Locations() []string { return f.locations }
+func (f *fakeProvider) MealTypes() []string { return f.mealTypes }
+
+func (f *fakeProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	return f.items, nil
+}
+
+func (f *fakeProvider) RefreshMenu(location, date, mealType string) ([]string, bool, error) {
+	f.refreshes++
+	return f.items, true, nil
+}
+
+// restoreConfig snapshots the mutable config lists and restores them when the test ends
+func restoreConfig(t *testing.T) {
+	t.Helper()
+	locationMap := maps.Clone(config.LocationMap)
+	locations := slices.Clone(config.ValidLocations)
+	mealTypes := slices.Clone(config.ValidMealTypes)
+	t.Cleanup(func() {
+		config.LocationMap = locationMap
+		config.ValidLocations = locations
+		config.ValidMealTypes = mealTypes
+	})
+}
+
+func TestRegistryRouting(t *testing.T) {
+	restoreConfig(t)
+
+	stanford := &fakeProvider{name: "stanford", locations: []string{"Branner Dining", "Lakeside Dining"},
+		mealTypes: []string{"Lunch", "Dinner"}, items: []string{"Pho"}}
+	other := &fakeProvider{name: "other", locations: []string{"North Commons", "Lakeside Dining"},
+		mealTypes: []string{"Lunch", "Late Night"}, items: []string{"Bagels"}}
+
+	registry := NewRegistry()
+	if skipped := registry.Register(stanford); len(skipped) != 0 {
+		t.Errorf("Register() skipped %v", skipped)
+	}
+	if skipped := registry.Register(other); !reflect.DeepEqual(skipped, []string{"Lakeside Dining"}) {
+		t.Errorf("Register() skipped %v, want [Lakeside Dining]", skipped)
+	}
+
+	// New locations and meal types are merged into the config lists
+	if !config.IsValidLocation("North Commons") || !config.IsValidMealType("Late Night") {
+		t.Error("Register() should merge the provider's locations and meal types into config")
+	}
+
+	tests := []struct {
+		location string
+		want     []string
+	}{
+		{"Branner Dining", []string{"Pho"}},
+		{"lakeside", []string{"Pho"}}, // first provider keeps a shared location
+		{"North Commons", []string{"Bagels"}},
+	}
+	for _, tt := range tests {
+		got, err := registry.GetMenu(tt.location, "1/15/2025", "Lunch")
+		if err != nil {
+			t.Fatalf("GetMenu(%q) error = %v", tt.location, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetMenu(%q) = %v, want %v", tt.location, got, tt.want)
+		}
+	}
+
+	// A meal type the provider doesn't serve yields an empty menu
+	got, err := registry.GetMenu("North Commons", "1/15/2025", "Dinner")
+	if err != nil || len(got) != 0 {
+		t.Errorf("GetMenu() for an unserved meal = %v, %v; want empty", got, err)
+	}
+
+	if _, err := registry.GetMenu("Stern Dining", "1/15/2025", "Lunch"); err == nil {
+		t.Error("GetMenu() should return error for a location no provider serves")
+	}
+
+	if len(registry.Providers()) != 2 {
+		t.Errorf("Providers() returned %d providers, want 2", len(registry.Providers()))
+	}
+}
+
+func TestRegistryOptionalCapabilities(t *testing.T) {
+	restoreConfig(t)
+
+	static, err := LoadStatic("testdata/static_menus.json")
+	if err != nil {
+		t.Fatalf("LoadStatic() error = %v", err)
+	}
+	refreshing := &fakeProvider{name: "refreshing", locations: []string{"Branner Dining"},
+		mealTypes: []string{"Lunch"}, items: []string{"Pho"}}
+
+	registry := NewRegistry()
+	registry.Register(refreshing)
+	registry.Register(static)
+
+	if dates, err := registry.AvailableDates("North Commons"); err != nil || len(dates) != 2 {
+		t.Errorf("AvailableDates() = %v, %v", dates, err)
+	}
+	if _, err := registry.AvailableDates("Branner Dining"); err == nil {
+		t.Error("AvailableDates() should return error for a provider without dates")
+	}
+
+	if _, changed, err := registry.RefreshMenu("Branner Dining", "1/15/2025", "Lunch"); err != nil || !changed || refreshing.refreshes != 1 {
+		t.Errorf("RefreshMenu() should use the provider's refresh, got changed=%v err=%v", changed, err)
+	}
+	items, changed, err := registry.RefreshMenu("North Commons", "1/15/2025", "Lunch")
+	if err != nil || changed || len(items) != 2 {
+		t.Errorf("RefreshMenu() fallback = %v, %v, %v", items, changed, err)
+	}
+}
+
+// detailedProvider knows each item's allergens
+type detailedProvider struct {
+	fakeProvider
+}
+
+func (d *detailedProvider) MenuItems(location, date, mealType string) ([]parser.Item, error) {
+	items := make([]parser.Item, len(d.items))
+	for i, name := range d.items {
+		items[i] = parser.Item{Name: name, Allergens: "soy"}
+	}
+	return items, nil
+}
+
+func (d *detailedProvider) ItemDetails(location, date, mealType, item string) (parser.Item, error) {
+	items, _ := d.MenuItems(location, date, mealType)
+	if found, ok := parser.FindItem(items, item); ok {
+		return found, nil
+	}
+	return parser.Item{}, fmt.Errorf("%s not found", item)
+}
+
+func TestRegistryMenuItems(t *testing.T) {
+	restoreConfig(t)
+
+	detailed := &detailedProvider{fakeProvider{name: "detailed", locations: []string{"Branner Dining"},
+		mealTypes: []string{"Lunch"}, items: []string{"Pho"}}}
+	plain := &fakeProvider{name: "plain", locations: []string{"North Commons"},
+		mealTypes: []string{"Lunch"}, items: []string{"Bagels"}}
+
+	registry := NewRegistry()
+	registry.Register(detailed)
+	registry.Register(plain)
+
+	items, err := registry.MenuItems("Branner Dining", "1/15/2025", "Lunch")
+	if err != nil || len(items) != 1 || items[0].Allergens != "soy" {
+		t.Errorf("MenuItems() = %+v, %v, want the provider's items", items, err)
+	}
+	items, err = registry.MenuItems("North Commons", "1/15/2025", "Lunch")
+	if err != nil || !reflect.DeepEqual(items, []parser.Item{{Name: "Bagels"}}) {
+		t.Errorf("MenuItems() = %+v, %v, want items built from names", items, err)
+	}
+	if items, err := registry.MenuItems("North Commons", "1/15/2025", "Dinner"); err != nil || len(items) != 0 {
+		t.Errorf("MenuItems() = %+v, %v, want an empty menu for an unserved meal", items, err)
+	}
+
+	if item, err := registry.ItemDetails("Branner Dining", "1/15/2025", "lunch", "pho"); err != nil || item.Allergens != "soy" {
+		t.Errorf("ItemDetails() = %+v, %v", item, err)
+	}
+	if item, err := registry.ItemDetails("North Commons", "1/15/2025", "Lunch", "BAGELS"); err != nil || item.Name != "Bagels" {
+		t.Errorf("ItemDetails() fallback = %+v, %v", item, err)
+	}
+	if _, err := registry.ItemDetails("North Commons", "1/15/2025", "Lunch", "Pho"); err == nil {
+		t.Error("ItemDetails() should fail for an item not on the menu")
+	}
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(config.ProviderSettings{Type: "static", Path: "testdata/static_menus.json"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if p.Name() != "example-university" {
+		t.Errorf("New() provider name = %q", p.Name())
+	}
+
+	if _, err := New(config.ProviderSettings{Type: "carrier-pigeon"}); err == nil {
+		t.Error("New() should return error for an unknown type")
+	}
+	if _, err := New(config.ProviderSettings{Type: "static"}); err == nil {
+		t.Error("New() should return error for a static provider without a path")
+	}
+}
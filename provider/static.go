@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bklieger/diningbot/dates"
+)
+
+// StaticMenus is the JSON format read by the static provider:
+// menus are keyed by location, then date, then meal type
+type StaticMenus struct {
+	Name      string                                    `json:"name"`
+	Locations []string                                  `json:"locations"`
+	MealTypes []string                                  `json:"mealTypes"`
+	Menus     map[string]map[string]map[string][]string `json:"menus"`
+}
+
+// Static is a provider serving menus from a JSON file, useful as a reference
+// implementation and for sites that publish menus as data
+type Static struct {
+	name      string
+	locations []string
+	mealTypes []string
+	menus     map[string][]string // location|date|mealType -> items
+	dates     map[string][]string // location -> dates with menus, in order
+}
+
+// LoadStatic reads a static provider from a JSON file
+func LoadStatic(path string) (*Static, error) {
+	if path == "" {
+		return nil, fmt.Errorf("static provider needs a path")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static menus: %w", err)
+	}
+	var menus StaticMenus
+	if err := json.Unmarshal(data, &menus); err != nil {
+		return nil, fmt.Errorf("invalid static menus %s: %w", path, err)
+	}
+	return NewStatic(menus)
+}
+
+// NewStatic builds a static provider, normalizing menu dates to M/D/YYYY.
+// Dates may be written in any format the dates package accepts, such as 2025-01-15.
+func NewStatic(menus StaticMenus) (*Static, error) {
+	if menus.Name == "" {
+		return nil, fmt.Errorf("static provider needs a name")
+	}
+	if len(menus.Locations) == 0 || len(menus.MealTypes) == 0 {
+		return nil, fmt.Errorf("static provider %s needs locations and mealTypes", menus.Name)
+	}
+
+	s := &Static{
+		name:      menus.Name,
+		locations: slices.Clone(menus.Locations),
+		mealTypes: slices.Clone(menus.MealTypes),
+		menus:     make(map[string][]string),
+		dates:     make(map[string][]string),
+	}
+	for location, byDate := range menus.Menus {
+		if !slices.Contains(s.locations, location) {
+			return nil, fmt.Errorf("static provider %s has menus for unlisted location %q", s.name, location)
+		}
+		for rawDate, byMeal := range byDate {
+			date, err := dates.Normalize(rawDate, dates.Now())
+			if err != nil {
+				return nil, fmt.Errorf("static provider %s: %w", s.name, err)
+			}
+			for mealType, items := range byMeal {
+				if !slices.Contains(s.mealTypes, mealType) {
+					return nil, fmt.Errorf("static provider %s has menus for unlisted meal type %q", s.name, mealType)
+				}
+				s.menus[location+"|"+date+"|"+mealType] = slices.Clone(items)
+			}
+			if !slices.Contains(s.dates[location], date) {
+				s.dates[location] = append(s.dates[location], date)
+			}
+		}
+	}
+	for location := range s.dates {
+		slices.SortFunc(s.dates[location], compareDates)
+	}
+	return s, nil
+}
+
+// Name returns the provider name from the file
+func (s *Static) Name() string {
+	return s.name
+}
+
+// Locations returns the locations listed in the file
+func (s *Static) Locations() []string {
+	return slices.Clone(s.locations)
+}
+
+// MealTypes returns the meal types listed in the file
+func (s *Static) MealTypes() []string {
+	return slices.Clone(s.mealTypes)
+}
+
+// GetMenu returns the items for a menu, or an empty list if the file has none
+func (s *Static) GetMenu(location, date, mealType string) ([]string, error) {
+	if !slices.Contains(s.locations, location) {
+		return nil, fmt.Errorf("invalid location: %s", location)
+	}
+	date, err := dates.Normalize(date, dates.Now())
+	if err != nil {
+		return nil, err
+	}
+	items, ok := s.menus[location+"|"+date+"|"+mealType]
+	if !ok {
+		return []string{}, nil
+	}
+	return slices.Clone(items), nil
+}
+
+// AvailableDates returns the dates the file has menus for at a location, in order
+func (s *Static) AvailableDates(location string) ([]string, error) {
+	if !slices.Contains(s.locations, location) {
+		return nil, fmt.Errorf("invalid location: %s", location)
+	}
+	return slices.Clone(s.dates[location]), nil
+}
+
+// compareDates orders M/D/YYYY dates chronologically
+func compareDates(a, b string) int {
+	ta, errA := dates.Resolve(a, dates.Now())
+	tb, errB := dates.Resolve(b, dates.Now())
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return ta.Compare(tb)
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadStatic(t *testing.T) {
+	s, err := LoadStatic("testdata/static_menus.json")
+	if err != nil {
+		t.Fatalf("LoadStatic() error = %v", err)
+	}
+	if s.Name() != "example-university" {
+		t.Errorf("Name() = %q", s.Name())
+	}
+	if !reflect.DeepEqual(s.Locations(), []string{"North Commons", "South Commons"}) {
+		t.Errorf("Locations() = %v", s.Locations())
+	}
+
+	items, err := s.GetMenu("North Commons", "1/15/2025", "Lunch")
+	if err != nil {
+		t.Fatalf("GetMenu() error = %v", err)
+	}
+	if !reflect.DeepEqual(items, []string{"Tomato Basil Soup", "Grilled Cheese"}) {
+		t.Errorf("GetMenu() = %v", items)
+	}
+
+	// Dates are accepted in any supported format
+	if items, _ := s.GetMenu("North Commons", "2025-01-16", "Dinner"); len(items) != 2 {
+		t.Errorf("GetMenu() with ISO date = %v", items)
+	}
+
+	// Missing menus are empty, not errors
+	items, err = s.GetMenu("South Commons", "1/20/2025", "Lunch")
+	if err != nil || items == nil || len(items) != 0 {
+		t.Errorf("GetMenu() for a missing menu = %v, %v; want empty list", items, err)
+	}
+
+	if _, err := s.GetMenu("Nowhere", "1/15/2025", "Lunch"); err == nil {
+		t.Error("GetMenu() should return error for an unknown location")
+	}
+
+	available, err := s.AvailableDates("North Commons")
+	if err != nil {
+		t.Fatalf("AvailableDates() error = %v", err)
+	}
+	if !reflect.DeepEqual(available, []string{"1/15/2025", "1/16/2025"}) {
+		t.Errorf("AvailableDates() = %v", available)
+	}
+}
+
+func TestNewStaticValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		menus StaticMenus
+	}{
+		{"no name", StaticMenus{Locations: []string{"A"}, MealTypes: []string{"Lunch"}}},
+		{"no locations", StaticMenus{Name: "x", MealTypes: []string{"Lunch"}}},
+		{"unlisted location", StaticMenus{Name: "x", Locations: []string{"A"}, MealTypes: []string{"Lunch"},
+			Menus: map[string]map[string]map[string][]string{"B": {"1/15/2025": {"Lunch": {"Soup"}}}}}},
+		{"unlisted meal type", StaticMenus{Name: "x", Locations: []string{"A"}, MealTypes: []string{"Lunch"},
+			Menus: map[string]map[string]map[string][]string{"A": {"1/15/2025": {"Supper": {"Soup"}}}}}},
+		{"bad date", StaticMenus{Name: "x", Locations: []string{"A"}, MealTypes: []string{"Lunch"},
+			Menus: map[string]map[string]map[string][]string{"A": {"someday": {"Lunch": {"Soup"}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStatic(tt.menus); err == nil {
+				t.Error("NewStatic() should return error")
+			}
+		})
+	}
+
+	if _, err := LoadStatic("testdata/missing.json"); err == nil {
+		t.Error("LoadStatic() should return error for a missing file")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+const (
+	// menuURITemplate addresses a single menu; the date segment accepts anything dates.Resolve does
+	menuURITemplate = "menu://{location}/{date}/{mealType}"
+	// locationsURI lists the valid locations and meal types
+	locationsURI = "menu://locations"
+)
+
+var menuTemplate = uritemplate.MustNew(menuURITemplate)
+
+// LocationsResource is the content of the menu://locations resource
+type LocationsResource struct {
+	Locations []string `json:"locations"`
+	MealTypes []string `json:"mealTypes"`
+}
+
+// menuURI builds the resource URI for a menu
+func menuURI(location, date, mealType string) string {
+	values := uritemplate.Values{}
+	values.Set("location", uritemplate.String(location))
+	values.Set("date", uritemplate.String(date))
+	values.Set("mealType", uritemplate.String(mealType))
+	uri, err := menuTemplate.Expand(values)
+	if err != nil {
+		// Expanding string values into a valid template cannot fail
+		panic(err)
+	}
+	return uri
+}
+
+// parseMenuURI extracts location, date, and meal type from a menu resource URI
+func parseMenuURI(uri string) (location, date, mealType string, ok bool) {
+	values := menuTemplate.Match(uri)
+	if values == nil {
+		return "", "", "", false
+	}
+	location = values.Get("location").String()
+	date = values.Get("date").String()
+	mealType = values.Get("mealType").String()
+	return location, date, mealType, location != "" && date != "" && mealType != ""
+}
+
+// resolveMenuURI validates a menu resource URI, resolves location and meal type
+// nicknames to canonical names, and resolves its date (M/D/YYYY,
+// YYYY-MM-DD, or a relative expression like "today") to M/D/YYYY.
+// It returns a resource-not-found error for URIs that don't name a valid menu.
+func resolveMenuURI(uri string) (location, date, mealType string, err error) {
+	location, date, mealType, ok := parseMenuURI(uri)
+	if !ok {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+	if location, err = config.NormalizeLocation(location); err != nil {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+	if mealType, err = config.NormalizeMealType(mealType); err != nil {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+
+	date, err = dates.Normalize(date, dates.Now())
+	if err != nil {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+	return location, date, mealType, nil
+}
+
+// ReadLocations serves the menu://locations resource
+func ReadLocations(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	data, err := json.Marshal(LocationsResource{
+		Locations: config.ValidLocations,
+		MealTypes: config.ValidMealTypes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// ReadMenu serves menu://{location}/{date}/{mealType} resources
+func ReadMenu(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	location, date, mealType, err := resolveMenuURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := initProviders(); err != nil {
+		return nil, err
+	}
+
+	items, err := menuProviders.GetMenu(location, date, mealType)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []string{}
+	}
+
+	data, err := json.Marshal(tools.GetMenuOutput{
+		Location: location,
+		Date:     date,
+		MealType: mealType,
+		Items:    items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// addResources registers the menu resources on the server
+func addResources(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		URI:         locationsURI,
+		Name:        "locations",
+		Title:       "Dining Hall Locations",
+		Description: "Valid dining hall locations and meal types",
+		MIMEType:    "application/json",
+	}, ReadLocations)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: menuURITemplate,
+		Name:        "menu",
+		Title:       "Dining Hall Menu",
+		Description: "Menu for a dining hall location, date (M/D/YYYY, YYYY-MM-DD, or relative like \"today\"), and meal type",
+		MIMEType:    "application/json",
+	}, ReadMenu)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// menuSubscriptions tracks which sessions subscribe to which menu resources
+// and refreshes those menus in the background, notifying on changes
+type menuSubscriptions struct {
+	mu   sync.Mutex
+	uris map[string]map[*mcp.ServerSession]bool
+	// sent is the menu each subscribed URI last had, so a change is noticed
+	// whether or not the old menu is still cached, and when a relative date
+	// like "today" moves to another day
+	sent map[string][]string
+}
+
+// newMenuSubscriptions creates an empty subscription tracker
+func newMenuSubscriptions() *menuSubscriptions {
+	return &menuSubscriptions{
+		uris: make(map[string]map[*mcp.ServerSession]bool),
+		sent: make(map[string][]string),
+	}
+}
+
+// Subscribe handles resources/subscribe for menu resources. The menu as it
+// is now is recorded in the background, so the first refresh has something
+// to compare with even if nobody has read the resource, without holding up
+// the request on the menu site.
+func (m *menuSubscriptions) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if uri != locationsURI {
+		location, date, mealType, err := resolveMenuURI(uri)
+		if err != nil {
+			return err
+		}
+		// The date is resolved now, so a "today" menu seeded after midnight
+		// is still the day it was subscribed on
+		if err := initProviders(); err == nil {
+			go m.seed(uri, location, date, mealType)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.uris[uri] == nil {
+		m.uris[uri] = make(map[*mcp.ServerSession]bool)
+	}
+	m.uris[uri][req.Session] = true
+	return nil
+}
+
+// seed records a subscribed URI's menu unless a refresh already has
+func (m *menuSubscriptions) seed(uri, location, date, mealType string) {
+	items, err := menuProviders.GetMenu(location, date, mealType)
+	if err != nil {
+		log.Printf("Failed to record %s for subscribers: %v", uri, err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, subscribed := m.uris[uri]; !subscribed {
+		return
+	}
+	if _, ok := m.sent[uri]; !ok {
+		m.sent[uri] = items
+	}
+}
+
+// Unsubscribe handles resources/unsubscribe for menu resources
+func (m *menuSubscriptions) Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(req.Params.URI, req.Session)
+	return nil
+}
+
+// remove drops a session's subscription; callers must hold m.mu
+func (m *menuSubscriptions) remove(uri string, session *mcp.ServerSession) {
+	sessions, ok := m.uris[uri]
+	if !ok {
+		return
+	}
+	delete(sessions, session)
+	if len(sessions) == 0 {
+		delete(m.uris, uri)
+		delete(m.sent, uri)
+	}
+}
+
+// subscribedMenuURIs returns menu URIs that still have a connected subscriber,
+// forgetting subscriptions of sessions that have gone away
+func (m *menuSubscriptions) subscribedMenuURIs(server *mcp.Server) []string {
+	connected := make(map[*mcp.ServerSession]bool)
+	for session := range server.Sessions() {
+		connected[session] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var uris []string
+	for uri, sessions := range m.uris {
+		for session := range sessions {
+			if !connected[session] {
+				m.remove(uri, session)
+			}
+		}
+		if _, ok := m.uris[uri]; ok && uri != locationsURI {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// Refresh re-fetches every subscribed menu and sends notifications/resources/updated
+// for those whose item list differs from the one the URI last had. A URI whose
+// menu hasn't been recorded yet, as when the site was down when it was
+// subscribed, is recorded without a notification.
+func (m *menuSubscriptions) Refresh(ctx context.Context, server *mcp.Server) {
+	uris := m.subscribedMenuURIs(server)
+	if len(uris) == 0 {
+		return
+	}
+	if err := initProviders(); err != nil {
+		log.Printf("Menu refresh skipped: failed to initialize providers: %v", err)
+		return
+	}
+
+	for _, uri := range uris {
+		location, date, mealType, err := resolveMenuURI(uri)
+		if err != nil {
+			continue
+		}
+
+		items, _, err := menuProviders.RefreshMenu(location, date, mealType)
+		if err != nil {
+			log.Printf("Menu refresh failed for %s: %v", uri, err)
+			continue
+		}
+
+		m.mu.Lock()
+		previous, seen := m.sent[uri]
+		changed := seen && !slices.Equal(previous, items)
+		if _, subscribed := m.uris[uri]; subscribed {
+			m.sent[uri] = items
+		}
+		m.mu.Unlock()
+
+		if changed {
+			if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+				log.Printf("Failed to notify update for %s: %v", uri, err)
+			}
+		}
+	}
+}
+
+// Run refreshes subscribed menus on every interval until ctx is cancelled
+func (m *menuSubscriptions) Run(ctx context.Context, server *mcp.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Refresh(ctx, server)
+		}
+	}
+}
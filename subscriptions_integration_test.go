@@ -0,0 +1,200 @@
+//go:build integration
+// +build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/provider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// changingProvider serves Branner Dining lunches that tests can change, by date
+type changingProvider struct {
+	mu    sync.Mutex
+	menus map[string][]string
+	down  bool
+	calls int
+}
+
+func (p *changingProvider) Name() string        { return "changing" }
+func (p *changingProvider) Locations() []string { return []string{"Branner Dining"} }
+func (p *changingProvider) MealTypes() []string { return []string{"Lunch"} }
+
+func (p *changingProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.down {
+		return nil, fmt.Errorf("site unreachable")
+	}
+	return p.menus[date], nil
+}
+
+func (p *changingProvider) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *changingProvider) fetched() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func (p *changingProvider) set(date string, items ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.menus[date] = items
+}
+
+// subscribe connects a client to a new server, subscribes it to uri and
+// returns the server with a channel of the URIs the client is told changed
+func subscribe(t *testing.T, uri string) (*mcp.Server, <-chan string) {
+	t.Helper()
+	ctx := context.Background()
+	saved := menuSubs
+	menuSubs = newMenuSubscriptions()
+	t.Cleanup(func() { menuSubs = saved })
+
+	server := setupServer()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	updated := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Subscribe(%s) error = %v", uri, err)
+	}
+	return server, updated
+}
+
+// waitRecorded waits for the menu a subscription records in the background
+func waitRecorded(t *testing.T, uri string) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		menuSubs.mu.Lock()
+		_, ok := menuSubs.sent[uri]
+		menuSubs.mu.Unlock()
+		if ok {
+			return
+		}
+	}
+	t.Fatalf("menu for %s was never recorded", uri)
+}
+
+// expectUpdate checks whether the client was told uri changed
+func expectUpdate(t *testing.T, updated <-chan string, uri string, want bool) {
+	t.Helper()
+	select {
+	case got := <-updated:
+		if !want || got != uri {
+			t.Errorf("notified of %s, want no notification", got)
+		}
+	case <-time.After(200 * time.Millisecond):
+		if want {
+			t.Errorf("no notification for %s", uri)
+		}
+	}
+}
+
+// TestSubscriptionNotifiesUnreadMenus tests that a menu nobody has read is
+// still notified when it changes after the subscription
+func TestSubscriptionNotifiesUnreadMenus(t *testing.T) {
+	changing := &changingProvider{menus: map[string][]string{"1/15/2025": {"Pho"}}}
+	registry := provider.NewRegistry()
+	registry.Register(changing)
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	uri := menuURI("Branner Dining", "1/15/2025", "Lunch")
+	server, updated := subscribe(t, uri)
+	waitRecorded(t, uri)
+
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, false)
+
+	changing.set("1/15/2025", "Pho", "Tofu")
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, true)
+
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, false)
+}
+
+// TestSubscriptionNotifiesNewDay tests that a "today" menu is notified when
+// the date changes to a day with a different menu
+func TestSubscriptionNotifiesNewDay(t *testing.T) {
+	changing := &changingProvider{menus: map[string][]string{
+		"1/15/2025": {"Pho"},
+		"1/16/2025": {"Tacos"},
+	}}
+	registry := provider.NewRegistry()
+	registry.Register(changing)
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	campus := dates.Campus
+	clock := dates.Clock
+	dates.Clock = func() time.Time { return time.Date(2025, 1, 15, 23, 0, 0, 0, campus) }
+	t.Cleanup(func() { dates.Clock = clock })
+
+	uri := menuURI("Branner Dining", "today", "Lunch")
+	server, updated := subscribe(t, uri)
+	waitRecorded(t, uri)
+
+	dates.Clock = func() time.Time { return time.Date(2025, 1, 16, 1, 0, 0, 0, campus) }
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, true)
+}
+
+// TestSubscriptionSiteDownWhenSubscribed tests that a menu that couldn't be
+// recorded when subscribed is recorded by the first refresh without a
+// notification
+func TestSubscriptionSiteDownWhenSubscribed(t *testing.T) {
+	changing := &changingProvider{menus: map[string][]string{"1/15/2025": {"Pho"}}, down: true}
+	registry := provider.NewRegistry()
+	registry.Register(changing)
+	saved := menuProviders
+	menuProviders = registry
+	t.Cleanup(func() { menuProviders = saved })
+
+	uri := menuURI("Branner Dining", "1/15/2025", "Lunch")
+	server, updated := subscribe(t, uri)
+	for deadline := time.Now().Add(time.Second); changing.fetched() == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("subscribing never fetched the menu")
+		}
+	}
+
+	changing.setDown(false)
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, false)
+
+	changing.set("1/15/2025", "Pho", "Tofu")
+	menuSubs.Refresh(context.Background(), server)
+	expectUpdate(t, updated, uri, true)
+}
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/bklieger/diningbot/health"
+)
+
+// ParserHealth reports whether menu pages are still being parsed, so a change
+// to the menu site's markup shows up as drift rather than as empty menus
+var ParserHealth = &Tool[struct{}, health.ParserStatus]{
+	Name:        "parser_health",
+	Description: "Check whether menu pages are still being parsed: reports drift when several dining halls return menu pages without any items, which usually means the menu site changed its layout",
+	Handle: func(ctx context.Context, call *Call, in struct{}) (health.ParserStatus, error) {
+		return call.ParserHealth(), nil
+	},
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/parser"
+)
+
+// GetItemDetailsInput defines the input for the get_item_details tool
+type GetItemDetailsInput struct {
+	Location string `json:"location" jsonschema:"The dining hall location name"`
+	Item     string `json:"item" jsonschema:"The menu item's name, matched case-insensitively"`
+	Date     string `json:"date,omitempty" jsonschema:"Date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'tomorrow'. If not provided, uses today's date"`
+	MealType string `json:"mealType,omitempty" jsonschema:"The meal type. If not provided, searches every meal of the day in order"`
+}
+
+// GetItemDetailsOutput defines the output for the get_item_details tool
+type GetItemDetailsOutput struct {
+	Location string      `json:"location"`
+	Date     string      `json:"date"`
+	MealType string      `json:"mealType"`
+	Item     parser.Item `json:"item"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// GetItemDetails looks up one menu item's ingredients, allergens and
+// nutrition label. Without a meal type the day's meals are searched in order
+// and the first one serving the item is used.
+var GetItemDetails = &Tool[GetItemDetailsInput, GetItemDetailsOutput]{
+	Name:        "get_item_details",
+	Description: "Get a menu item's ingredients, allergens and nutrition facts (serving size, calories, macronutrients)",
+	UsesMenus:   true,
+	Validate: []Validator[GetItemDetailsInput]{
+		Location(func(in *GetItemDetailsInput) *string { return &in.Location }),
+		OptionalMealType(func(in *GetItemDetailsInput) *string { return &in.MealType }),
+		Date(func(in *GetItemDetailsInput) *string { return &in.Date }),
+		Required("item", func(in *GetItemDetailsInput) *string { return &in.Item }),
+	},
+	Handle: getItemDetails,
+	Failed: func(in GetItemDetailsInput, out GetItemDetailsOutput, err error) GetItemDetailsOutput {
+		// Calls failing before the search report the input as validated so far
+		if out.Location == "" {
+			out = GetItemDetailsOutput{Location: in.Location, Date: in.Date, MealType: in.MealType}
+		}
+		out.Error = err.Error()
+		return out
+	},
+}
+
+func getItemDetails(ctx context.Context, call *Call, in GetItemDetailsInput) (GetItemDetailsOutput, error) {
+	output := GetItemDetailsOutput{Location: in.Location, Date: in.Date, MealType: in.MealType}
+	mealTypes := config.ValidMealTypes
+	if in.MealType != "" {
+		mealTypes = []string{in.MealType}
+	}
+
+	for _, mealType := range mealTypes {
+		// Search item names first, so finding the meal fetches no nutrition labels
+		names, err := call.Menus.GetMenu(in.Location, in.Date, mealType)
+		if err != nil {
+			output.MealType = mealType
+			return output, fmt.Errorf("Error fetching menu: %w", err)
+		}
+		if !slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, strings.TrimSpace(in.Item)) }) {
+			continue
+		}
+
+		output.MealType = mealType
+		item, err := call.Menus.ItemDetails(in.Location, in.Date, mealType, in.Item)
+		output.Item = item
+		if err != nil {
+			return output, fmt.Errorf("Error fetching item details: %w", err)
+		}
+		return output, nil
+	}
+
+	if len(mealTypes) == 1 {
+		return output, fmt.Errorf("%q is not on the %s menu at %s on %s", in.Item, mealTypes[0], in.Location, in.Date)
+	}
+	return output, fmt.Errorf("%q is not on any menu at %s on %s", in.Item, in.Location, in.Date)
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/format"
+	"github.com/bklieger/diningbot/parser"
+	"github.com/bklieger/diningbot/utils"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// GetMenuInput defines the input for the get_menu tool
+type GetMenuInput struct {
+	Location string `json:"location" jsonschema:"The dining hall location name"`
+	Date     string `json:"date,omitempty" jsonschema:"Date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'tomorrow' or 'next friday'. If not provided, uses today's date"`
+	MealType string `json:"mealType" jsonschema:"The meal type"`
+	Details  bool   `json:"details,omitempty" jsonschema:"Also return each item's ingredients, allergens and detail link, plus nutrition if the server fetches nutrition labels"`
+	Format   string `json:"format,omitempty" jsonschema:"Also render the menu as text content in this format (default: JSON of the structured output)"`
+}
+
+// GetMenuOutput defines the output for the get_menu tool
+type GetMenuOutput struct {
+	Location string   `json:"location"`
+	Date     string   `json:"date"`
+	MealType string   `json:"mealType"`
+	Items    []string `json:"items"`
+	// Details are the structured items, when requested
+	Details []parser.Item `json:"details,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// GetMenu fetches the menu for a specific location, date, and meal type
+var GetMenu = &Tool[GetMenuInput, GetMenuOutput]{
+	Name:        "get_menu",
+	Description: "Get the menu for a specific dining hall location, date, and meal type",
+	UsesMenus:   true,
+	Validate: []Validator[GetMenuInput]{
+		Location(func(in *GetMenuInput) *string { return &in.Location }),
+		MealType(func(in *GetMenuInput) *string { return &in.MealType }),
+		Format(func(in *GetMenuInput) *string { return &in.Format }),
+		Date(func(in *GetMenuInput) *string { return &in.Date }),
+	},
+	Handle: func(ctx context.Context, call *Call, in GetMenuInput) (GetMenuOutput, error) {
+		items, err := call.Menus.GetMenu(in.Location, in.Date, in.MealType)
+		if err != nil {
+			return GetMenuOutput{
+				Location: in.Location,
+				Date:     in.Date,
+				MealType: in.MealType,
+				Error:    err.Error(),
+			}, fmt.Errorf("Error fetching menu: %w", err)
+		}
+		output := GetMenuOutput{
+			Location: in.Location,
+			Date:     in.Date,
+			MealType: in.MealType,
+			Items:    nonNil(items),
+		}
+		if in.Details {
+			// The menu was just fetched, so its items come from the same cached page
+			details, err := call.Menus.MenuItems(in.Location, in.Date, in.MealType)
+			if err != nil {
+				return output, fmt.Errorf("Error fetching menu details: %w", err)
+			}
+			output.Details = details
+		}
+		return output, nil
+	},
+	Failed: func(in GetMenuInput, out GetMenuOutput, err error) GetMenuOutput {
+		// A bad date is reported along with the rest of the input
+		var invalid *DateError
+		if errors.As(err, &invalid) {
+			out = GetMenuOutput{Location: in.Location, Date: in.Date, MealType: in.MealType, Error: invalid.Err.Error()}
+		}
+		out.Items = nonNil(out.Items)
+		return out
+	},
+	Render: func(in GetMenuInput, out GetMenuOutput) (string, error) {
+		return render(in.Format, []format.Menu{{
+			Location: out.Location,
+			Date:     out.Date,
+			MealType: out.MealType,
+			Items:    out.Items,
+		}})
+	},
+}
+
+// GetMenusRangeInput defines the input for the get_menus_range tool
+type GetMenusRangeInput struct {
+	Location  string `json:"location" jsonschema:"The dining hall location name"`
+	MealType  string `json:"mealType" jsonschema:"The meal type"`
+	Days      int    `json:"days,omitempty" jsonschema:"Number of days to fetch"`
+	StartDate string `json:"startDate,omitempty" jsonschema:"Start date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'tomorrow' or 'this weekend'. If not provided, uses today's date"`
+	Format    string `json:"format,omitempty" jsonschema:"Also render the menus as text content in this format (default: JSON of the structured output)"`
+}
+
+// GetMenusRangeOutput defines the output for the get_menus_range tool
+type GetMenusRangeOutput struct {
+	Location string              `json:"location"`
+	MealType string              `json:"mealType"`
+	Menus    map[string][]string `json:"menus"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// GetMenusRange fetches menus for multiple days
+var GetMenusRange = &Tool[GetMenusRangeInput, GetMenusRangeOutput]{
+	Name:        "get_menus_range",
+	Description: "Get menus for multiple days for a specific dining hall location and meal type",
+	UsesMenus:   true,
+	Schema: func(schema *jsonschema.Schema, env *Env) {
+		schema.Properties["days"].Description = fmt.Sprintf("Number of days to fetch (default: %d, max: %d)", env.Settings.Server.DefaultRangeDays, env.Settings.Server.MaxRangeDays)
+	},
+	Validate: []Validator[GetMenusRangeInput]{
+		Location(func(in *GetMenusRangeInput) *string { return &in.Location }),
+		MealType(func(in *GetMenusRangeInput) *string { return &in.MealType }),
+		Format(func(in *GetMenusRangeInput) *string { return &in.Format }),
+	},
+	Handle: getMenusRange,
+	Failed: func(in GetMenusRangeInput, out GetMenusRangeOutput, err error) GetMenusRangeOutput {
+		if out.Menus == nil {
+			out.Menus = map[string][]string{}
+		}
+		return out
+	},
+	Render: func(in GetMenusRangeInput, out GetMenusRangeOutput) (string, error) {
+		return render(in.Format, format.FromRange(out.Location, out.MealType, out.Menus))
+	},
+}
+
+func getMenusRange(ctx context.Context, call *Call, in GetMenusRangeInput) (GetMenusRangeOutput, error) {
+	// Determine start date; multi-day expressions like "this weekend" also imply a length
+	days := in.Days
+	var startTime time.Time
+	if in.StartDate != "" {
+		span, err := dates.ResolveRange(in.StartDate, dates.Now())
+		if err != nil {
+			return GetMenusRangeOutput{}, fmt.Errorf("Invalid start date: %w", err)
+		}
+		startTime = span.Start
+		if days <= 0 && span.Days > 1 {
+			days = span.Days
+		}
+	} else {
+		startTime = dates.Now()
+	}
+
+	// Set default days
+	if days <= 0 {
+		days = call.Settings.Server.DefaultRangeDays
+	}
+	if days > call.Settings.Server.MaxRangeDays {
+		days = call.Settings.Server.MaxRangeDays
+	}
+
+	// Skip dates the site doesn't offer; if its date list is unavailable, try every day
+	var offered map[string]bool
+	if available, err := call.Menus.AvailableDates(in.Location); err == nil {
+		offered = make(map[string]bool, len(available))
+		for _, d := range available {
+			offered[d] = true
+		}
+	}
+
+	// Fetch menus for each day; a menu that can't be fetched is empty, but if
+	// none can be the call fails, so an outage isn't mistaken for empty menus
+	menus := make(map[string][]string)
+	failed := 0
+	var lastErr error
+	for i := 0; i < days; i++ {
+		date := utils.FormatDate(startTime.AddDate(0, 0, i))
+		if offered != nil && !offered[date] {
+			continue
+		}
+		items, err := call.Menus.GetMenu(in.Location, date, in.MealType)
+		if err != nil {
+			failed++
+			lastErr = err
+		}
+		menus[date] = nonNil(items)
+	}
+	if failed > 0 && failed == len(menus) {
+		return GetMenusRangeOutput{
+			Location: in.Location,
+			MealType: in.MealType,
+			Error:    lastErr.Error(),
+		}, fmt.Errorf("Error fetching menus: %w", lastErr)
+	}
+
+	return GetMenusRangeOutput{
+		Location: in.Location,
+		MealType: in.MealType,
+		Menus:    menus,
+	}, nil
+}
+
+// ListAvailableDatesInput defines the input for the list_available_dates tool
+type ListAvailableDatesInput struct {
+	Location string `json:"location" jsonschema:"The dining hall location name"`
+}
+
+// ListAvailableDatesOutput defines the output for the list_available_dates tool
+type ListAvailableDatesOutput struct {
+	Location string   `json:"location"`
+	Dates    []string `json:"dates"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ListAvailableDates lists the dates the menu site has menus for at a location
+var ListAvailableDates = &Tool[ListAvailableDatesInput, ListAvailableDatesOutput]{
+	Name:        "list_available_dates",
+	Description: "List the dates that have published menus for a dining hall location",
+	UsesMenus:   true,
+	// The providers resolve the location, so an unknown one fails the fetch
+	Handle: func(ctx context.Context, call *Call, in ListAvailableDatesInput) (ListAvailableDatesOutput, error) {
+		dateList, err := call.Menus.AvailableDates(in.Location)
+		if err != nil {
+			return ListAvailableDatesOutput{
+				Location: in.Location,
+				Error:    err.Error(),
+			}, fmt.Errorf("Error fetching available dates: %w", err)
+		}
+		return ListAvailableDatesOutput{Location: in.Location, Dates: nonNil(dateList)}, nil
+	},
+	Failed: func(in ListAvailableDatesInput, out ListAvailableDatesOutput, err error) ListAvailableDatesOutput {
+		out.Dates = nonNil(out.Dates)
+		return out
+	},
+}
+
+// render renders menus in the named format, or returns "" without a format
+// so the text content is the output's JSON
+func render(name string, menus []format.Menu) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	return format.Render(format.Format(name), menus)
+}
+
+// nonNil returns an empty list for a nil one, since output schemas don't
+// allow null lists
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
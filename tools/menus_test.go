@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bklieger/diningbot/provider"
+)
+
+func TestGetMenusRange(t *testing.T) {
+	env := testEnv(t)
+
+	out, err := GetMenusRange.Call(context.Background(), env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Lunch", Days: 5})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	// Only the dates the provider has menus for are fetched
+	want := map[string][]string{
+		"1/15/2025": {"Pho", "Spring Rolls"},
+		"1/16/2025": {"Tacos"},
+	}
+	if !reflect.DeepEqual(out.Menus, want) {
+		t.Errorf("Menus = %v, want %v", out.Menus, want)
+	}
+
+	out, err = GetMenusRange.Call(context.Background(), env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Dinner", StartDate: "tomorrow", Days: 1})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if items, ok := out.Menus["1/16/2025"]; !ok || items == nil || len(items) != 0 {
+		t.Errorf("Menus = %#v, want an empty menu for 1/16/2025", out.Menus)
+	}
+
+	if _, err := GetMenusRange.Call(context.Background(), env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Lunch", StartDate: "someday"}); err == nil {
+		t.Error("Call() with a bad start date succeeded")
+	}
+}
+
+func TestListAvailableDates(t *testing.T) {
+	env := testEnv(t)
+
+	// The providers resolve nicknames; the output echoes the location as given
+	out, err := ListAvailableDates.Call(context.Background(), env, ListAvailableDatesInput{Location: "Branner Dining"})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if want := []string{"1/15/2025", "1/16/2025"}; out.Location != "Branner Dining" || !reflect.DeepEqual(out.Dates, want) {
+		t.Errorf("Call() = %+v, want dates %v", out, want)
+	}
+}
+
+// flakyProvider fails to fetch menus for the dates in down
+type flakyProvider struct {
+	down map[string]bool
+}
+
+func (p *flakyProvider) Name() string        { return "flaky" }
+func (p *flakyProvider) Locations() []string { return []string{"Branner Dining"} }
+func (p *flakyProvider) MealTypes() []string { return []string{"Lunch"} }
+
+func (p *flakyProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	if p.down[date] {
+		return nil, errors.New("site unreachable")
+	}
+	return []string{"Pho"}, nil
+}
+
+func TestGetMenusRangeFetchErrors(t *testing.T) {
+	env := testEnv(t)
+	flaky := &flakyProvider{down: map[string]bool{"1/15/2025": true}}
+	registry := provider.NewRegistry()
+	registry.Register(flaky)
+	env.Providers = func() (*provider.Registry, error) { return registry, nil }
+
+	// A day that fails is empty
+	out, err := GetMenusRange.Call(context.Background(), env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Lunch", Days: 2})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	want := map[string][]string{"1/15/2025": {}, "1/16/2025": {"Pho"}}
+	if !reflect.DeepEqual(out.Menus, want) {
+		t.Errorf("Menus = %v, want %v", out.Menus, want)
+	}
+
+	// When every day fails, so does the call
+	flaky.down["1/16/2025"] = true
+	out, err = GetMenusRange.Call(context.Background(), env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Lunch", Days: 2})
+	if err == nil || !strings.Contains(err.Error(), "site unreachable") {
+		t.Fatalf("Call() error = %v, want the fetch error", err)
+	}
+	if out.Error != "site unreachable" || out.Menus == nil || len(out.Menus) != 0 {
+		t.Errorf("Call() = %+v, want the error and no menus", out)
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics counts calls per tool. It is safe for concurrent use, and a nil
+// *Metrics counts nothing.
+type Metrics struct {
+	mu    sync.Mutex
+	tools map[string]*counts
+}
+
+type counts struct {
+	calls, errors int
+	duration      time.Duration
+}
+
+// Stats are one tool's call counts
+type Stats struct {
+	Calls  int `json:"calls"`
+	Errors int `json:"errors"`
+	// AverageMillis is the mean time a call took, in milliseconds
+	AverageMillis float64 `json:"averageMillis"`
+}
+
+// NewMetrics creates metrics without any calls
+func NewMetrics() *Metrics {
+	return &Metrics{tools: make(map[string]*counts)}
+}
+
+// record counts a call
+func (m *Metrics) record(tool string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := m.tools[tool]
+	if c == nil {
+		c = &counts{}
+		m.tools[tool] = c
+	}
+	c.calls++
+	c.duration += duration
+	if err != nil {
+		c.errors++
+	}
+}
+
+// Snapshot returns the counts of the tools called so far, by name
+func (m *Metrics) Snapshot() map[string]Stats {
+	stats := make(map[string]Stats)
+	if m == nil {
+		return stats
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for tool, c := range m.tools {
+		stats[tool] = Stats{
+			Calls:         c.calls,
+			Errors:        c.errors,
+			AverageMillis: float64(c.duration) / float64(c.calls) / float64(time.Millisecond),
+		}
+	}
+	return stats
+}
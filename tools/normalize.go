@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/format"
+	"github.com/bklieger/diningbot/planner"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// normalizedArguments lists the tool arguments that accept nicknames, in the
+// order they are checked
+var normalizedArguments = []struct {
+	name      string
+	normalize func(string) (string, error)
+}{
+	{"location", config.NormalizeLocation},
+	{"locations", config.NormalizeLocation},
+	{"mealType", config.NormalizeMealType},
+	{"mealTypes", config.NormalizeMealType},
+	{"format", normalizeFormat},
+	{"restrictions", planner.NormalizeRestriction},
+}
+
+// normalizeFormat canonicalizes output format names, like "md" for "markdown"
+func normalizeFormat(name string) (string, error) {
+	f, err := format.Parse(name)
+	return string(f), err
+}
+
+// NormalizeArguments is receiving middleware that rewrites location and meal
+// type nicknames and restriction spellings in tool arguments (e.g. "FloMo",
+// "supper", "Gluten Free") to their canonical names, so they pass the schema
+// enums. Unknown values become a tool error with
+// "did you mean" suggestions instead of a bare enum validation failure.
+func NormalizeArguments(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if !ok || len(params.Arguments) == 0 {
+			return next(ctx, method, req)
+		}
+
+		var args map[string]any
+		if err := json.Unmarshal(params.Arguments, &args); err != nil {
+			// Leave malformed arguments to the SDK's own validation
+			return next(ctx, method, req)
+		}
+
+		changed, err := canonicalizeArguments(args)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: err.Error()},
+				},
+			}, nil
+		}
+		if changed {
+			raw, err := json.Marshal(args)
+			if err != nil {
+				return nil, err
+			}
+			params.Arguments = raw
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// canonicalizeArguments canonicalizes the arguments in normalizedArguments in place,
+// reporting whether any value changed
+func canonicalizeArguments(args map[string]any) (bool, error) {
+	changed := false
+	for _, arg := range normalizedArguments {
+		normalize := arg.normalize
+		switch v := args[arg.name].(type) {
+		case string:
+			canonical, err := normalize(v)
+			if err != nil {
+				return false, err
+			}
+			if canonical != v {
+				args[arg.name] = canonical
+				changed = true
+			}
+		case []any:
+			for i, elem := range v {
+				s, ok := elem.(string)
+				if !ok {
+					continue
+				}
+				canonical, err := normalize(s)
+				if err != nil {
+					return false, err
+				}
+				if canonical != s {
+					v[i] = canonical
+					changed = true
+				}
+			}
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/parser"
+	"github.com/bklieger/diningbot/planner"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// defaultPlanMeals are planned when plan_meals is given no meal types
+var defaultPlanMeals = []string{"Breakfast", "Lunch", "Dinner"}
+
+// maxPlanLabelFetches bounds how many nutrition labels one plan_meals call
+// fetches; labels already cached don't count
+const maxPlanLabelFetches = 60
+
+// PlanMealsInput defines the input for the plan_meals tool
+type PlanMealsInput struct {
+	Date            string   `json:"date,omitempty" jsonschema:"Date in M/D/YYYY or YYYY-MM-DD format, or a relative expression like 'tomorrow'. If not provided, uses today's date"`
+	Calories        float64  `json:"calories,omitempty" jsonschema:"Calorie target for the day"`
+	Protein         float64  `json:"protein,omitempty" jsonschema:"Protein target for the day, in grams"`
+	Locations       []string `json:"locations" jsonschema:"Dining halls to eat at; each meal is planned at one of them"`
+	MealTypes       []string `json:"mealTypes,omitempty" jsonschema:"Meals to plan, in order (default: Breakfast, Lunch, Dinner)"`
+	Restrictions    []string `json:"restrictions,omitempty" jsonschema:"Dietary restrictions, checked against item names, ingredients and allergens"`
+	Avoid           []string `json:"avoid,omitempty" jsonschema:"Other foods to avoid, like 'mushroom'"`
+	MaxItemsPerMeal int      `json:"maxItemsPerMeal,omitempty" jsonschema:"Most items to pick per meal"`
+}
+
+// PlanMealsOutput defines the output for the plan_meals tool
+type PlanMealsOutput struct {
+	Date string       `json:"date"`
+	Plan planner.Plan `json:"plan"`
+	// WithoutNutrition counts menu items skipped for lack of a nutrition label
+	WithoutNutrition int `json:"withoutNutrition"`
+	// Notes report menus that couldn't be fetched and labels left unread
+	Notes []string `json:"notes"`
+	Error string   `json:"error,omitempty"`
+}
+
+// PlanMeals picks items across a day's meals at the given halls to approach
+// calorie and protein targets. Items need nutrition labels, so their labels
+// are fetched (and cached) as needed, up to maxPlanLabelFetches per call.
+var PlanMeals = &Tool[PlanMealsInput, PlanMealsOutput]{
+	Name:        "plan_meals",
+	Description: "Plan a day's meals at one or more dining halls to approach a calorie and protein target, respecting dietary restrictions; returns the items and nutrition totals per meal",
+	UsesMenus:   true,
+	Schema: func(schema *jsonschema.Schema, env *Env) {
+		schema.Properties["locations"].MinItems = jsonschema.Ptr(1)
+		schema.Properties["maxItemsPerMeal"].Description = fmt.Sprintf("Most items to pick per meal (default: %d, max: %d)", planner.DefaultMaxItemsPerMeal, planner.MaxItemsPerMealLimit)
+	},
+	Validate: []Validator[PlanMealsInput]{
+		Date(func(in *PlanMealsInput) *string { return &in.Date }),
+		func(in *PlanMealsInput) error {
+			if len(in.Locations) == 0 {
+				return errors.New("at least one location is required")
+			}
+			return nil
+		},
+		Locations(func(in *PlanMealsInput) *[]string { return &in.Locations }),
+		MealTypes(func(in *PlanMealsInput) *[]string { return &in.MealTypes }),
+	},
+	Handle: planMeals,
+	Failed: func(in PlanMealsInput, out PlanMealsOutput, err error) PlanMealsOutput {
+		if out.Notes == nil {
+			out = PlanMealsOutput{Date: in.Date, Plan: planner.Plan{Meals: []planner.MealPlan{}}, Notes: []string{}}
+		}
+		out.Error = err.Error()
+		return out
+	},
+}
+
+func planMeals(ctx context.Context, call *Call, in PlanMealsInput) (PlanMealsOutput, error) {
+	output := PlanMealsOutput{Date: in.Date, Plan: planner.Plan{Meals: []planner.MealPlan{}}, Notes: []string{}}
+
+	mealTypes := in.MealTypes
+	if len(mealTypes) == 0 {
+		for _, m := range defaultPlanMeals {
+			if config.IsValidMealType(m) {
+				mealTypes = append(mealTypes, m)
+			}
+		}
+	}
+
+	request := planner.Request{
+		Target:          planner.Target{Calories: in.Calories, Protein: in.Protein},
+		Restrictions:    in.Restrictions,
+		Avoid:           in.Avoid,
+		MaxItemsPerMeal: in.MaxItemsPerMeal,
+	}
+	// Check the request before fetching any menus
+	if _, err := request.Plan(); err != nil {
+		return PlanMealsOutput{}, err
+	}
+
+	// Labels are looked up a menu at a time, within one budget for the call
+	budget, unread := maxPlanLabelFetches, 0
+	for _, mealType := range mealTypes {
+		meal := planner.Meal{MealType: mealType}
+		for _, location := range in.Locations {
+			if err := ctx.Err(); err != nil {
+				return PlanMealsOutput{}, err
+			}
+			items, err := call.Menus.MenuItems(location, in.Date, mealType)
+			if err != nil {
+				output.Notes = append(output.Notes, fmt.Sprintf("%s %s: %v", location, mealType, err))
+				continue
+			}
+			fetched, skipped, err := call.Menus.FillNutrition(ctx, location, items, budget)
+			if err != nil {
+				output.Notes = append(output.Notes, fmt.Sprintf("%s %s: %v", location, mealType, err))
+			}
+			budget -= fetched
+			unread += skipped
+			for _, item := range items {
+				if item.Nutrition == nil || item.Nutrition.Calories == nil {
+					output.WithoutNutrition++
+					continue
+				}
+				meal.Items = append(meal.Items, planItem(location, item, item.Nutrition))
+			}
+		}
+		request.Meals = append(request.Meals, meal)
+	}
+	if err := ctx.Err(); err != nil {
+		return PlanMealsOutput{}, err
+	}
+	if unread > 0 {
+		output.Notes = append(output.Notes, fmt.Sprintf("%d items were left out because at most %d nutrition labels are fetched per plan; planning again reuses the labels already fetched", unread, maxPlanLabelFetches))
+	}
+
+	plan, err := request.Plan()
+	if err != nil {
+		return output, err
+	}
+	output.Plan = plan
+	return output, nil
+}
+
+// planItem converts a menu item with a nutrition label for the planner
+func planItem(location string, item parser.Item, nutrition *parser.Nutrition) planner.Item {
+	value := func(v *float64) float64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	return planner.Item{
+		Name:          item.Name,
+		Location:      location,
+		Calories:      value(nutrition.Calories),
+		Protein:       value(nutrition.Protein),
+		TotalFat:      value(nutrition.TotalFat),
+		Carbohydrates: value(nutrition.Carbohydrates),
+		Ingredients:   item.Ingredients,
+		Allergens:     item.Allergens,
+	}
+}
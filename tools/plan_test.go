@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bklieger/diningbot/parser"
+	"github.com/bklieger/diningbot/provider"
+)
+
+// labelProvider serves the same menu everywhere and reads a label for every
+// item, counting the labels it reads
+type labelProvider struct {
+	items   []string
+	fetched int
+}
+
+func (p *labelProvider) Name() string        { return "labels" }
+func (p *labelProvider) Locations() []string { return []string{"Branner Dining", "Lakeside Dining"} }
+func (p *labelProvider) MealTypes() []string { return []string{"Breakfast", "Lunch", "Dinner"} }
+
+func (p *labelProvider) GetMenu(location, date, mealType string) ([]string, error) {
+	return p.items, nil
+}
+
+func (p *labelProvider) MenuItems(location, date, mealType string) ([]parser.Item, error) {
+	items := make([]parser.Item, len(p.items))
+	for i, name := range p.items {
+		items[i] = parser.Item{Name: name}
+	}
+	return items, nil
+}
+
+func (p *labelProvider) ItemDetails(location, date, mealType, item string) (parser.Item, error) {
+	return parser.Item{Name: item}, nil
+}
+
+func (p *labelProvider) FillNutrition(ctx context.Context, items []parser.Item, limit int) (fetched, skipped int) {
+	calories, protein := 300.0, 20.0
+	for i := range items {
+		if fetched >= limit || ctx.Err() != nil {
+			skipped++
+			continue
+		}
+		items[i].Nutrition = &parser.Nutrition{Calories: &calories, Protein: &protein}
+		fetched++
+	}
+	p.fetched += fetched
+	return fetched, skipped
+}
+
+func TestPlanMealsLabelLimit(t *testing.T) {
+	env := testEnv(t)
+	labels := &labelProvider{items: make([]string, 15)}
+	for i := range labels.items {
+		labels.items[i] = "Dish " + string(rune('A'+i))
+	}
+	registry := provider.NewRegistry()
+	registry.Register(labels)
+	env.Providers = func() (*provider.Registry, error) { return registry, nil }
+
+	// 2 halls x 3 meals x 15 items is more labels than one call reads
+	out, err := PlanMeals.Call(context.Background(), env, PlanMealsInput{Locations: []string{"Branner Dining", "Lakeside Dining"}, Calories: 2000})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if labels.fetched != maxPlanLabelFetches {
+		t.Errorf("labels fetched = %d, want %d", labels.fetched, maxPlanLabelFetches)
+	}
+	if out.WithoutNutrition != 90-maxPlanLabelFetches {
+		t.Errorf("WithoutNutrition = %d, want %d", out.WithoutNutrition, 90-maxPlanLabelFetches)
+	}
+	if len(out.Notes) != 1 || !strings.Contains(out.Notes[0], "30 items") {
+		t.Errorf("Notes = %v, want the unread labels reported", out.Notes)
+	}
+
+	// A cancelled call stops instead of fetching menus and labels
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	labels.fetched = 0
+	if _, err := PlanMeals.Call(ctx, env, PlanMealsInput{Locations: []string{"Branner Dining"}, Calories: 2000}); err == nil {
+		t.Error("Call() with a cancelled context succeeded")
+	}
+	if labels.fetched != 0 {
+		t.Errorf("labels fetched = %d after cancelling, want 0", labels.fetched)
+	}
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/format"
+	"github.com/bklieger/diningbot/planner"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// argumentEnums returns the allowed values of tool arguments by name. They
+// are read when tools are registered, after site discovery may have added
+// locations and meal types. Array arguments restrict their items.
+func argumentEnums() map[string][]string {
+	return map[string][]string{
+		"location":     config.ValidLocations,
+		"locations":    config.ValidLocations,
+		"mealType":     config.ValidMealTypes,
+		"mealTypes":    config.ValidMealTypes,
+		"format":       format.Names(),
+		"restrictions": planner.Restrictions(),
+	}
+}
+
+// InputSchema derives a tool's input schema from its input type, with the
+// arguments in argumentEnums restricted to their allowed values. Property
+// descriptions come from jsonschema struct tags, and fields without
+// omitempty are required. It panics if T can't be described, like
+// mcp.AddTool.
+func InputSchema[T any]() *jsonschema.Schema {
+	schema := OutputSchema[T]()
+	for name, values := range argumentEnums() {
+		property := schema.Properties[name]
+		if property == nil {
+			continue
+		}
+		if property.Items != nil {
+			property = property.Items
+		}
+		property.Enum = make([]any, len(values))
+		for i, v := range values {
+			property.Enum[i] = v
+		}
+	}
+	return schema
+}
+
+// OutputSchema derives a tool's output schema from its output type. It
+// panics if T can't be described, like mcp.AddTool.
+func OutputSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Sprintf("deriving schema: %v", err))
+	}
+	return schema
+}
@@ -0,0 +1,187 @@
+// Package tools declares diningbot's MCP tools. A tool is a Tool value naming
+// its input and output types, validators that check and canonicalize the
+// input, a handler and an optional text renderer. The package derives each
+// tool's schemas from its types, turns errors into tool errors carrying the
+// tool's output, and logs and counts calls, so a tool holds only its own
+// logic and can be called directly, by the REST API and command line or in
+// tests, without an MCP session.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/health"
+	"github.com/bklieger/diningbot/provider"
+	"github.com/bklieger/diningbot/watchlist"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Env is the server state tools use
+type Env struct {
+	// Providers returns the menu providers, initializing them on first use
+	Providers func() (*provider.Registry, error)
+	Settings  *config.Settings
+	Watches   *watchlist.Store
+	// Scan, if set, asks the background scanner to check the watchlist soon,
+	// so a new watch doesn't wait for the next scheduled scan
+	Scan func()
+	// ParserHealth reports whether menu pages are still being parsed
+	ParserHealth func() health.ParserStatus
+	// Metrics counts calls; nil counts nothing
+	Metrics *Metrics
+}
+
+// Call is a tool call being handled
+type Call struct {
+	*Env
+	// Menus are the menu providers, set for tools that use menus
+	Menus *provider.Registry
+}
+
+// Validator checks a tool's input, canonicalizing it in place
+type Validator[In any] func(in *In) error
+
+// Tool declares an MCP tool taking In and returning Out
+type Tool[In, Out any] struct {
+	Name        string
+	Description string
+	// UsesMenus initializes the menu providers before the input is validated
+	UsesMenus bool
+	// Schema adjusts the input schema derived from In, for what struct tags
+	// can't express
+	Schema func(schema *jsonschema.Schema, env *Env)
+	// Validate checks the input, in order, before Handle is called
+	Validate []Validator[In]
+	// Handle does the tool's work. An error fails the call with its message,
+	// and the output returned with it is the failed call's structured output.
+	Handle func(ctx context.Context, call *Call, in In) (Out, error)
+	// Failed, if set, completes the structured output of a failed call, given
+	// the input as validated so far and the output Handle returned, which is
+	// Out's zero value if the call failed before Handle ran
+	Failed func(in In, out Out, err error) Out
+	// Render returns the text content of a successful call. Without it, or
+	// when it returns "", the text content is the output's JSON.
+	Render func(in In, out Out) (string, error)
+}
+
+// Definition is a tool of any input and output types
+type Definition interface {
+	// Register adds the tool to a server
+	Register(server *mcp.Server, env *Env)
+}
+
+// All lists the tools, in the order clients see them
+var All = []Definition{
+	GetMenu,
+	GetMenusRange,
+	ListAvailableDates,
+	GetItemDetails,
+	PlanMeals,
+	WatchDish,
+	UnwatchDish,
+	ListWatches,
+	ParserHealth,
+}
+
+// Register adds all tools to a server. Their schemas list the locations and
+// meal types known now, so it is called after site discovery and provider
+// registration.
+func Register(server *mcp.Server, env *Env) {
+	for _, tool := range All {
+		tool.Register(server, env)
+	}
+}
+
+// Register adds the tool to a server. The SDK validates arguments against
+// the input schema, and every output against the output schema, including
+// the output of a failed call, so Failed must return a valid Out.
+func (t *Tool[In, Out]) Register(server *mcp.Server, env *Env) {
+	input := InputSchema[In]()
+	if t.Schema != nil {
+		t.Schema(input, env)
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:         t.Name,
+		Description:  t.Description,
+		InputSchema:  input,
+		OutputSchema: OutputSchema[Out](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		in, out, err := t.call(ctx, env, in)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: err.Error()},
+				},
+			}, out, nil
+		}
+		return t.result(in, out), out, nil
+	})
+}
+
+// Call validates the input and handles it, like a call from an MCP client.
+// A failed call returns its structured output with the error.
+func (t *Tool[In, Out]) Call(ctx context.Context, env *Env, in In) (Out, error) {
+	_, out, err := t.call(ctx, env, in)
+	return out, err
+}
+
+// call handles a call, returning the validated input with the output
+func (t *Tool[In, Out]) call(ctx context.Context, env *Env, in In) (In, Out, error) {
+	start := time.Now()
+	out, err := t.handle(ctx, env, &in)
+	env.Metrics.record(t.Name, time.Since(start), err)
+	if err != nil {
+		log.Printf("Tool %s failed: %v", t.Name, err)
+		if t.Failed != nil {
+			out = t.Failed(in, out, err)
+		}
+	}
+	return in, out, err
+}
+
+// handle initializes the menu providers if needed, validates the input in
+// place and runs the handler
+func (t *Tool[In, Out]) handle(ctx context.Context, env *Env, in *In) (Out, error) {
+	var out Out
+	call := &Call{Env: env}
+	if t.UsesMenus {
+		menus, err := env.Providers()
+		if err != nil {
+			return out, fmt.Errorf("Failed to initialize client: %w", err)
+		}
+		call.Menus = menus
+	}
+	for _, validate := range t.Validate {
+		if err := validate(in); err != nil {
+			return out, err
+		}
+	}
+	return t.Handle(ctx, call, *in)
+}
+
+// result returns the rendered text content of a successful call, or nil to
+// let the SDK use the output's JSON
+func (t *Tool[In, Out]) result(in In, out Out) *mcp.CallToolResult {
+	if t.Render == nil {
+		return nil
+	}
+	text, err := t.Render(in, out)
+	if err != nil {
+		log.Printf("Tool %s: rendering failed, returning JSON: %v", t.Name, err)
+		return nil
+	}
+	if text == "" {
+		return nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+}
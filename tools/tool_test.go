@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/provider"
+	"github.com/bklieger/diningbot/watchlist"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// testEnv returns an Env serving a static menu for Branner Dining
+func testEnv(t *testing.T) *Env {
+	t.Helper()
+	static, err := provider.NewStatic(provider.StaticMenus{
+		Name:      "test",
+		Locations: []string{"Branner Dining"},
+		MealTypes: []string{"Lunch", "Dinner"},
+		Menus: map[string]map[string]map[string][]string{
+			"Branner Dining": {
+				"2025-01-15": {"Lunch": {"Pho", "Spring Rolls"}},
+				"2025-01-16": {"Lunch": {"Tacos"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStatic() error = %v", err)
+	}
+	registry := provider.NewRegistry()
+	registry.Register(static)
+
+	clock := dates.Clock
+	dates.Clock = func() time.Time { return time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { dates.Clock = clock })
+
+	return &Env{
+		Providers: func() (*provider.Registry, error) { return registry, nil },
+		Settings:  config.DefaultSettings(),
+		Watches:   watchlist.NewStore(),
+		Metrics:   NewMetrics(),
+	}
+}
+
+func TestCallValidatesInput(t *testing.T) {
+	env := testEnv(t)
+
+	out, err := GetMenu.Call(context.Background(), env, GetMenuInput{Location: "branner", MealType: "lunch", Date: "2025-01-15"})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if out.Location != "Branner Dining" || out.MealType != "Lunch" || out.Date != "1/15/2025" {
+		t.Errorf("Call() input not canonicalized: %+v", out)
+	}
+	if strings.Join(out.Items, ",") != "Pho,Spring Rolls" {
+		t.Errorf("Call() items = %v", out.Items)
+	}
+
+	tests := []struct {
+		name string
+		in   GetMenuInput
+		want string
+	}{
+		{"bad location", GetMenuInput{Location: "nowhere", MealType: "Lunch"}, "nowhere"},
+		{"bad meal", GetMenuInput{Location: "Branner Dining", MealType: "elevenses"}, "elevenses"},
+		{"bad date", GetMenuInput{Location: "Branner Dining", MealType: "Lunch", Date: "someday"}, "Invalid date"},
+		{"bad format", GetMenuInput{Location: "Branner Dining", MealType: "Lunch", Format: "pdf"}, "pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetMenu.Call(context.Background(), env, tt.in); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Call() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallProviderError(t *testing.T) {
+	env := testEnv(t)
+	env.Providers = func() (*provider.Registry, error) { return nil, errors.New("site down") }
+
+	_, err := GetMenu.Call(context.Background(), env, GetMenuInput{Location: "Branner Dining", MealType: "Lunch"})
+	if err == nil || err.Error() != "Failed to initialize client: site down" {
+		t.Errorf("Call() error = %v", err)
+	}
+
+	// Tools without menus don't initialize the providers
+	if _, err := ListWatches.Call(context.Background(), env, struct{}{}); err != nil {
+		t.Errorf("ListWatches.Call() error = %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	env := testEnv(t)
+
+	GetMenu.Call(context.Background(), env, GetMenuInput{Location: "Branner Dining", MealType: "Lunch"})
+	GetMenu.Call(context.Background(), env, GetMenuInput{Location: "nowhere", MealType: "Lunch"})
+	ListWatches.Call(context.Background(), env, struct{}{})
+
+	stats := env.Metrics.Snapshot()
+	if got := stats["get_menu"]; got.Calls != 2 || got.Errors != 1 {
+		t.Errorf("get_menu stats = %+v, want 2 calls and 1 error", got)
+	}
+	if got := stats["list_watches"]; got.Calls != 1 || got.Errors != 0 {
+		t.Errorf("list_watches stats = %+v, want 1 call and no errors", got)
+	}
+	if _, ok := stats["plan_meals"]; ok {
+		t.Errorf("plan_meals has stats without being called")
+	}
+
+	// A nil Metrics counts nothing
+	env.Metrics = nil
+	if _, err := ListWatches.Call(context.Background(), env, struct{}{}); err != nil {
+		t.Errorf("Call() with nil metrics error = %v", err)
+	}
+}
+
+// connect registers the tools on a server and returns a connected client session
+func connect(t *testing.T, env *Env) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(NormalizeArguments)
+	Register(server, env)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server.Connect() error = %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestRegister(t *testing.T) {
+	env := testEnv(t)
+	session := connect(t, env)
+	ctx := context.Background()
+
+	list, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	if len(list.Tools) != len(All) {
+		t.Errorf("ListTools() returned %d tools, want %d", len(list.Tools), len(All))
+	}
+	for _, tool := range list.Tools {
+		if tool.InputSchema == nil || tool.OutputSchema == nil {
+			t.Errorf("%s is missing a schema", tool.Name)
+		}
+	}
+
+	// Rendered text content, with the structured output alongside
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_menu",
+		Arguments: map[string]any{"location": "Branner Dining", "mealType": "Lunch", "date": "1/15/2025", "format": "markdown"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("CallTool() failed: %v", res.Content)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "Spring Rolls") || strings.HasPrefix(text, "{") {
+		t.Errorf("text content = %q, want a markdown menu", text)
+	}
+	if res.StructuredContent == nil {
+		t.Error("CallTool() has no structured content")
+	}
+
+	// Failed calls are tool errors with the message, and their output reports the error
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_menu",
+		Arguments: map[string]any{"location": "Branner Dining", "mealType": "Lunch", "date": "someday"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !res.IsError {
+		t.Fatalf("CallTool() = %+v, want a tool error", res)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.HasPrefix(text, "Invalid date") {
+		t.Errorf("error text = %q", text)
+	}
+	output, _ := res.StructuredContent.(map[string]any)
+	if output["date"] != "someday" || output["error"] == nil || output["items"] == nil {
+		t.Errorf("structured content = %v, want the input with the error and no items", res.StructuredContent)
+	}
+}
+
+func TestRegisterNormalizesRestrictions(t *testing.T) {
+	env := testEnv(t)
+	session := connect(t, env)
+
+	// Spellings NormalizeRestriction accepts pass the restrictions enum
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "plan_meals",
+		Arguments: map[string]any{"locations": []string{"Branner Dining"}, "date": "2025-01-15", "calories": 600, "mealTypes": []string{"Lunch"}, "restrictions": []string{"Gluten Free", "dairy_free"}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if res.IsError {
+		t.Errorf("CallTool() failed: %v", res.Content[0].(*mcp.TextContent).Text)
+	}
+
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "plan_meals",
+		Arguments: map[string]any{"locations": []string{"Branner Dining"}, "date": "2025-01-15", "calories": 600, "restrictions": []string{"carnivore"}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "unknown dietary restriction") {
+		t.Errorf("CallTool() = %+v, want an unknown restriction error", res)
+	}
+}
+
+func TestFailedOutputs(t *testing.T) {
+	env := testEnv(t)
+	ctx := context.Background()
+
+	out, err := GetMenu.Call(ctx, env, GetMenuInput{Location: "nowhere", MealType: "Lunch"})
+	if err == nil || out.Items == nil || out.Error != "" || out.Location != "" {
+		t.Errorf("GetMenu.Call() = %+v, %v, want only empty items for invalid input", out, err)
+	}
+
+	rangeOut, err := GetMenusRange.Call(ctx, env, GetMenusRangeInput{Location: "Branner Dining", MealType: "Lunch", StartDate: "someday"})
+	if err == nil || rangeOut.Menus == nil {
+		t.Errorf("GetMenusRange.Call() = %+v, %v, want empty menus", rangeOut, err)
+	}
+
+	dates, err := ListAvailableDates.Call(ctx, env, ListAvailableDatesInput{Location: "nowhere"})
+	if err == nil || dates.Location != "nowhere" || dates.Dates == nil || dates.Error == "" {
+		t.Errorf("ListAvailableDates.Call() = %+v, %v, want the location with the error", dates, err)
+	}
+
+	item, err := GetItemDetails.Call(ctx, env, GetItemDetailsInput{Location: "branner", Date: "2025-01-15", Item: "Burrito"})
+	if err == nil || item.Location != "Branner Dining" || item.Date != "1/15/2025" || item.Error != err.Error() {
+		t.Errorf("GetItemDetails.Call() = %+v, %v, want the validated input with the error", item, err)
+	}
+
+	watch, err := WatchDish.Call(ctx, env, WatchDishInput{Dish: " ", Days: 3})
+	if err == nil || watch.Watch.Days != 3 || watch.Error != err.Error() {
+		t.Errorf("WatchDish.Call() = %+v, %v, want the requested watch with the error", watch, err)
+	}
+
+	unwatch, err := UnwatchDish.Call(ctx, env, UnwatchDishInput{ID: "nope"})
+	if err == nil || unwatch.Removed || unwatch.Error != err.Error() {
+		t.Errorf("UnwatchDish.Call() = %+v, %v, want the error", unwatch, err)
+	}
+
+	plan, err := PlanMeals.Call(ctx, env, PlanMealsInput{Date: "2025-01-15"})
+	if err == nil || plan.Date != "1/15/2025" || plan.Notes == nil || plan.Plan.Meals == nil || plan.Error != err.Error() {
+		t.Errorf("PlanMeals.Call() = %+v, %v, want the date with the error", plan, err)
+	}
+}
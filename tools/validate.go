@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/format"
+)
+
+// Validators take a function returning the input field they check, like
+//
+//	Location(func(in *GetMenuInput) *string { return &in.Location })
+
+// Required fails when a string field is empty
+func Required[In any](name string, field func(*In) *string) Validator[In] {
+	return func(in *In) error {
+		if *field(in) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+// Location canonicalizes a location, accepting nicknames like "FloMo"
+func Location[In any](field func(*In) *string) Validator[In] {
+	return func(in *In) error {
+		p := field(in)
+		location, err := config.NormalizeLocation(*p)
+		if err != nil {
+			return err
+		}
+		*p = location
+		return nil
+	}
+}
+
+// Locations canonicalizes a list of locations, dropping repeats
+func Locations[In any](field func(*In) *[]string) Validator[In] {
+	return func(in *In) error {
+		p := field(in)
+		var locations []string
+		for _, l := range *p {
+			location, err := config.NormalizeLocation(l)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(locations, location) {
+				locations = append(locations, location)
+			}
+		}
+		*p = locations
+		return nil
+	}
+}
+
+// MealType canonicalizes a meal type, accepting aliases like "supper"
+func MealType[In any](field func(*In) *string) Validator[In] {
+	return func(in *In) error {
+		p := field(in)
+		mealType, err := config.NormalizeMealType(*p)
+		if err != nil {
+			return err
+		}
+		*p = mealType
+		return nil
+	}
+}
+
+// OptionalMealType canonicalizes a meal type unless it is empty
+func OptionalMealType[In any](field func(*In) *string) Validator[In] {
+	meal := MealType(field)
+	return func(in *In) error {
+		if *field(in) == "" {
+			return nil
+		}
+		return meal(in)
+	}
+}
+
+// MealTypes canonicalizes a list of meal types
+func MealTypes[In any](field func(*In) *[]string) Validator[In] {
+	return func(in *In) error {
+		for i, m := range *field(in) {
+			mealType, err := config.NormalizeMealType(m)
+			if err != nil {
+				return err
+			}
+			(*field(in))[i] = mealType
+		}
+		return nil
+	}
+}
+
+// DateError reports a date that can't be parsed
+type DateError struct {
+	Err error
+}
+
+func (e *DateError) Error() string { return "Invalid date: " + e.Err.Error() }
+
+func (e *DateError) Unwrap() error { return e.Err }
+
+// Date normalizes a date to M/D/YYYY, accepting relative expressions like
+// "tomorrow". An empty date is today.
+func Date[In any](field func(*In) *string) Validator[In] {
+	return func(in *In) error {
+		p := field(in)
+		if *p == "" {
+			*p = dates.Today()
+			return nil
+		}
+		date, err := dates.Normalize(*p, dates.Now())
+		if err != nil {
+			return &DateError{Err: err}
+		}
+		*p = date
+		return nil
+	}
+}
+
+// Format canonicalizes an output format name, like "md" for "markdown",
+// unless it is empty
+func Format[In any](field func(*In) *string) Validator[In] {
+	return func(in *In) error {
+		p := field(in)
+		if *p == "" {
+			return nil
+		}
+		f, err := format.Parse(*p)
+		if err != nil {
+			return err
+		}
+		*p = string(f)
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bklieger/diningbot/watchlist"
+)
+
+// WatchDishInput defines the input for the watch_dish tool
+type WatchDishInput struct {
+	Dish      string   `json:"dish" jsonschema:"Dish name to watch for, matched case-insensitively as a substring (e.g., chicken tikka masala)"`
+	Days      int      `json:"days,omitempty" jsonschema:"Number of upcoming days to watch, including today (default: 3, max: 14)"`
+	Locations []string `json:"locations,omitempty" jsonschema:"Dining hall locations to watch. If not provided, watches all locations"`
+	MealTypes []string `json:"mealTypes,omitempty" jsonschema:"Meal types to watch. If not provided, watches all meal types"`
+}
+
+// WatchDishOutput defines the output for the watch_dish tool
+type WatchDishOutput struct {
+	Watch watchlist.Watch `json:"watch"`
+	Error string          `json:"error,omitempty"`
+}
+
+// WatchDish registers a dish on the watchlist
+var WatchDish = &Tool[WatchDishInput, WatchDishOutput]{
+	Name:        "watch_dish",
+	Description: "Watch for a dish and get notified when it appears on an upcoming menu at any dining hall",
+	Handle: func(ctx context.Context, call *Call, in WatchDishInput) (WatchDishOutput, error) {
+		// The store validates and canonicalizes the watch
+		watch, err := call.Watches.Add(watchlist.Watch{
+			Dish:      in.Dish,
+			Days:      in.Days,
+			Locations: in.Locations,
+			MealTypes: in.MealTypes,
+		})
+		if err != nil {
+			return WatchDishOutput{}, fmt.Errorf("Invalid watch: %w", err)
+		}
+
+		// Check right away so the caller doesn't wait for the next scan
+		if call.Scan != nil {
+			call.Scan()
+		}
+		return WatchDishOutput{Watch: watch}, nil
+	},
+	Failed: func(in WatchDishInput, out WatchDishOutput, err error) WatchDishOutput {
+		// A rejected watch reports the watch as requested
+		out.Watch = watchlist.Watch{Dish: in.Dish, Days: in.Days, Locations: in.Locations, MealTypes: in.MealTypes}
+		out.Error = err.Error()
+		return out
+	},
+}
+
+// UnwatchDishInput defines the input for the unwatch_dish tool
+type UnwatchDishInput struct {
+	ID string `json:"id" jsonschema:"ID of the watch to remove, as returned by watch_dish or list_watches"`
+}
+
+// UnwatchDishOutput defines the output for the unwatch_dish tool
+type UnwatchDishOutput struct {
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// UnwatchDish removes a dish from the watchlist
+var UnwatchDish = &Tool[UnwatchDishInput, UnwatchDishOutput]{
+	Name:        "unwatch_dish",
+	Description: "Stop watching for a dish",
+	Handle: func(ctx context.Context, call *Call, in UnwatchDishInput) (UnwatchDishOutput, error) {
+		if !call.Watches.Remove(in.ID) {
+			return UnwatchDishOutput{}, fmt.Errorf("Unknown watch: %s", in.ID)
+		}
+		return UnwatchDishOutput{Removed: true}, nil
+	},
+	Failed: func(in UnwatchDishInput, out UnwatchDishOutput, err error) UnwatchDishOutput {
+		out.Error = err.Error()
+		return out
+	},
+}
+
+// ListWatchesOutput defines the output for the list_watches tool
+type ListWatchesOutput struct {
+	Watches []watchlist.Watch `json:"watches"`
+}
+
+// ListWatches returns all registered watches
+var ListWatches = &Tool[struct{}, ListWatchesOutput]{
+	Name:        "list_watches",
+	Description: "List all watched dishes",
+	Handle: func(ctx context.Context, call *Call, in struct{}) (ListWatchesOutput, error) {
+		return ListWatchesOutput{Watches: call.Watches.List()}, nil
+	},
+}
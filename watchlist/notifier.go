@@ -0,0 +1,110 @@
+package watchlist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Notifier delivers alerts to some destination
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// MCPLogNotifier sends alerts as MCP logging notifications to every connected session
+type MCPLogNotifier struct {
+	Server *mcp.Server
+}
+
+// Notify sends the alert to all sessions that have enabled logging
+func (n *MCPLogNotifier) Notify(ctx context.Context, alert Alert) error {
+	var firstErr error
+	for session := range n.Server.Sessions() {
+		err := session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "notice",
+			Logger: "watchlist",
+			Data:   alert,
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WebhookNotifier POSTs alerts as JSON to an outbound webhook URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a default HTTP client
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// webhookPayload is the JSON body sent to webhooks
+type webhookPayload struct {
+	Text  string `json:"text"`
+	Alert Alert  `json:"alert"`
+}
+
+// Notify posts the alert to the webhook
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(webhookPayload{Text: alert.String(), Alert: alert})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier emails alerts through an SMTP server
+type SMTPNotifier struct {
+	Addr string // host:port
+	From string
+	To   []string
+	Auth smtp.Auth // optional
+}
+
+// Notify emails the alert to all recipients
+func (n *SMTPNotifier) Notify(ctx context.Context, alert Alert) error {
+	if len(n.To) == 0 {
+		return fmt.Errorf("no recipients configured")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + n.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.To, ", ") + "\r\n")
+	msg.WriteString("Subject: DiningBot: " + alert.Dish + " spotted at " + alert.Location + "\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(alert.String() + "\r\n")
+
+	return smtp.SendMail(n.Addr, n.Auth, n.From, n.To, []byte(msg.String()))
+}
@@ -0,0 +1,195 @@
+package watchlist
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var testAlert = Alert{
+	WatchID:  "w1",
+	Dish:     "tikka masala",
+	Item:     "Chicken Tikka Masala",
+	Location: "Branner Dining",
+	Date:     "1/15/2025",
+	MealType: "Dinner",
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %v", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decode error: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got.Alert != testAlert {
+		t.Errorf("payload alert = %+v, want %+v", got.Alert, testAlert)
+	}
+	if !strings.Contains(got.Text, "Chicken Tikka Masala") {
+		t.Errorf("payload text = %q, want item name", got.Text)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	if err := n.Notify(context.Background(), testAlert); err == nil {
+		t.Error("Notify() should return error on 500 status")
+	}
+}
+
+// startFakeSMTP runs a minimal SMTP server that accepts one message and sends its DATA on the channel
+func startFakeSMTP(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		write("220 localhost ESMTP")
+
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					messages <- data.String()
+					write("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				inData = true
+				write("354 End data with <CR><LF>.<CR><LF>")
+			case strings.HasPrefix(cmd, "QUIT"):
+				write("221 Bye")
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), messages
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, messages := startFakeSMTP(t)
+
+	n := &SMTPNotifier{
+		Addr: addr,
+		From: "bot@example.com",
+		To:   []string{"user@example.com"},
+	}
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if !strings.Contains(msg, "Subject: DiningBot: tikka masala spotted at Branner Dining") {
+			t.Errorf("message missing subject: %s", msg)
+		}
+		if !strings.Contains(msg, testAlert.String()) {
+			t.Errorf("message missing body: %s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for SMTP message")
+	}
+}
+
+func TestSMTPNotifierNoRecipients(t *testing.T) {
+	n := &SMTPNotifier{Addr: "127.0.0.1:0", From: "bot@example.com"}
+	if err := n.Notify(context.Background(), testAlert); err == nil {
+		t.Error("Notify() should return error without recipients")
+	}
+}
+
+func TestMCPLogNotifier(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+
+	received := make(chan *mcp.LoggingMessageParams, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect error: %v", err)
+	}
+	defer serverSession.Close()
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect error: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLoggingLevel error: %v", err)
+	}
+
+	n := &MCPLogNotifier{Server: server}
+	if err := n.Notify(ctx, testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	select {
+	case params := <-received:
+		if params.Logger != "watchlist" {
+			t.Errorf("Logger = %q, want watchlist", params.Logger)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for logging notification")
+	}
+}
@@ -0,0 +1,334 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bklieger/diningbot/config"
+	"github.com/bklieger/diningbot/dates"
+	"github.com/bklieger/diningbot/utils"
+)
+
+const (
+	// DefaultDays is the look-ahead window used when a watch doesn't set one
+	DefaultDays = 3
+	// MaxDays caps how far ahead a watch may look
+	MaxDays = 14
+)
+
+// Watch is a dish a user wants to be alerted about
+type Watch struct {
+	ID        string   `json:"id"`
+	Dish      string   `json:"dish"`
+	Days      int      `json:"days"`
+	Locations []string `json:"locations,omitempty"`
+	MealTypes []string `json:"mealTypes,omitempty"`
+}
+
+// Matches reports whether a menu item matches the watched dish (case-insensitive substring)
+func (w *Watch) Matches(item string) bool {
+	return strings.Contains(strings.ToLower(item), strings.ToLower(w.Dish))
+}
+
+// watchesLocation reports whether the watch covers a location
+func (w *Watch) watchesLocation(location string) bool {
+	return len(w.Locations) == 0 || contains(w.Locations, location)
+}
+
+// watchesMealType reports whether the watch covers a meal type
+func (w *Watch) watchesMealType(mealType string) bool {
+	return len(w.MealTypes) == 0 || contains(w.MealTypes, mealType)
+}
+
+// Alert is emitted when a watched dish shows up on a menu
+type Alert struct {
+	WatchID  string `json:"watchId"`
+	Dish     string `json:"dish"`
+	Item     string `json:"item"`
+	Location string `json:"location"`
+	Date     string `json:"date"`
+	MealType string `json:"mealType"`
+}
+
+// String renders the alert as a one-line human-readable message
+func (a Alert) String() string {
+	return fmt.Sprintf("%s is on the %s menu at %s on %s", a.Item, a.MealType, a.Location, a.Date)
+}
+
+// key identifies an alert for deduplication
+func (a Alert) key() string {
+	return a.WatchID + "|" + a.Location + "|" + a.Date + "|" + a.MealType + "|" + a.Item
+}
+
+// Store provides thread-safe storage for watches
+type Store struct {
+	mu      sync.RWMutex
+	watches map[string]*Watch
+	nextID  int
+}
+
+// NewStore creates an empty watch store
+func NewStore() *Store {
+	return &Store{
+		watches: make(map[string]*Watch),
+	}
+}
+
+// Add validates and registers a watch, assigning it an ID.
+// Location and meal type nicknames are resolved to canonical names.
+func (s *Store) Add(w Watch) (Watch, error) {
+	w.Dish = strings.TrimSpace(w.Dish)
+	if w.Dish == "" {
+		return Watch{}, fmt.Errorf("dish is required")
+	}
+	if w.Days <= 0 {
+		w.Days = DefaultDays
+	}
+	if w.Days > MaxDays {
+		w.Days = MaxDays
+	}
+	locations := make([]string, len(w.Locations))
+	for i, location := range w.Locations {
+		canonical, err := config.NormalizeLocation(location)
+		if err != nil {
+			return Watch{}, err
+		}
+		locations[i] = canonical
+	}
+	mealTypes := make([]string, len(w.MealTypes))
+	for i, mealType := range w.MealTypes {
+		canonical, err := config.NormalizeMealType(mealType)
+		if err != nil {
+			return Watch{}, err
+		}
+		mealTypes[i] = canonical
+	}
+	if len(w.Locations) > 0 {
+		w.Locations = locations
+	}
+	if len(w.MealTypes) > 0 {
+		w.MealTypes = mealTypes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	w.ID = fmt.Sprintf("w%d", s.nextID)
+	stored := w
+	s.watches[w.ID] = &stored
+	return w, nil
+}
+
+// Remove deletes a watch by ID, reporting whether it existed
+func (s *Store) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.watches[id]; !exists {
+		return false
+	}
+	delete(s.watches, id)
+	return true
+}
+
+// List returns a copy of all watches ordered by ID
+func (s *Store) List() []Watch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Watch, 0, len(s.watches))
+	for _, w := range s.watches {
+		result = append(result, *w)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].ID) != len(result[j].ID) {
+			return len(result[i].ID) < len(result[j].ID)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// MenuFetcher is the subset of the dining client the scanner needs
+type MenuFetcher interface {
+	GetMenu(location, date, mealType string) ([]string, error)
+}
+
+// Scanner periodically checks upcoming menus for watched dishes
+type Scanner struct {
+	Store     *Store
+	Fetcher   MenuFetcher
+	Notifiers []Notifier
+	// Now returns the current time; defaults to dates.Now (campus timezone)
+	Now func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]time.Time // alert key -> menu date, for dedup and pruning
+	// wake asks Run for a scan before the next tick; it holds at most one request
+	wake chan struct{}
+}
+
+// NewScanner creates a scanner over the given store and fetcher
+func NewScanner(store *Store, fetcher MenuFetcher, notifiers ...Notifier) *Scanner {
+	return &Scanner{
+		Store:     store,
+		Fetcher:   fetcher,
+		Notifiers: notifiers,
+		Now:       dates.Now,
+		sent:      make(map[string]time.Time),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Scan checks upcoming menus once and delivers any new alerts.
+// It returns the alerts that were delivered during this scan.
+func (s *Scanner) Scan(ctx context.Context) []Alert {
+	watches := s.Store.List()
+	if len(watches) == 0 {
+		return nil
+	}
+
+	maxDays := 0
+	for _, w := range watches {
+		if w.Days > maxDays {
+			maxDays = w.Days
+		}
+	}
+
+	now := s.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	s.pruneSent(today)
+
+	var alerts []Alert
+	for i := 0; i < maxDays; i++ {
+		day := today.AddDate(0, 0, i)
+		date := utils.FormatDate(day)
+		for _, location := range config.ValidLocations {
+			for _, mealType := range config.ValidMealTypes {
+				if ctx.Err() != nil {
+					return alerts
+				}
+
+				interested := watchesFor(watches, location, mealType, i)
+				if len(interested) == 0 {
+					continue
+				}
+
+				items, err := s.Fetcher.GetMenu(location, date, mealType)
+				if err != nil {
+					log.Printf("watchlist: failed to fetch %s %s %s: %v", location, date, mealType, err)
+					continue
+				}
+
+				for _, w := range interested {
+					for _, item := range items {
+						if !w.Matches(item) {
+							continue
+						}
+						alert := Alert{
+							WatchID:  w.ID,
+							Dish:     w.Dish,
+							Item:     item,
+							Location: location,
+							Date:     date,
+							MealType: mealType,
+						}
+						if s.markSent(alert, day) {
+							s.deliver(ctx, alert)
+							alerts = append(alerts, alert)
+						}
+					}
+				}
+			}
+		}
+	}
+	return alerts
+}
+
+// Trigger asks Run to scan as soon as it is free, without waiting for the
+// next interval. Requests made while a scan is pending are merged into it,
+// so many triggers cause one scan.
+func (s *Scanner) Trigger() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run scans immediately, then on every interval and whenever triggered,
+// until ctx is cancelled
+func (s *Scanner) Run(ctx context.Context, interval time.Duration) {
+	s.Scan(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Scan(ctx)
+		case <-s.wake:
+			s.Scan(ctx)
+		}
+	}
+}
+
+// deliver sends an alert to every notifier, logging failures
+func (s *Scanner) deliver(ctx context.Context, alert Alert) {
+	for _, n := range s.Notifiers {
+		if err := n.Notify(ctx, alert); err != nil {
+			log.Printf("watchlist: notifier failed for %q: %v", alert.String(), err)
+		}
+	}
+}
+
+// markSent records an alert and reports whether it had not been sent before
+func (s *Scanner) markSent(alert Alert, day time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent == nil {
+		s.sent = make(map[string]time.Time)
+	}
+	key := alert.key()
+	if _, exists := s.sent[key]; exists {
+		return false
+	}
+	s.sent[key] = day
+	return true
+}
+
+// pruneSent forgets alerts for menu dates that have already passed
+func (s *Scanner) pruneSent(today time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, day := range s.sent {
+		if day.Before(today) {
+			delete(s.sent, key)
+		}
+	}
+}
+
+// watchesFor returns the watches interested in a location and meal type dayOffset days ahead
+func watchesFor(watches []Watch, location, mealType string, dayOffset int) []Watch {
+	var result []Watch
+	for _, w := range watches {
+		if dayOffset < w.Days && w.watchesLocation(location) && w.watchesMealType(mealType) {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package watchlist
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFetcher serves canned menus keyed by location|date|mealType
+type fakeFetcher struct {
+	mu    sync.Mutex
+	menus map[string][]string
+	calls int
+}
+
+func (f *fakeFetcher) GetMenu(location, date, mealType string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.menus[location+"|"+date+"|"+mealType], nil
+}
+
+// recordingNotifier records delivered alerts
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+func TestStore_AddListRemove(t *testing.T) {
+	store := NewStore()
+
+	w, err := store.Add(Watch{Dish: "  Chicken Tikka Masala "})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if w.ID == "" {
+		t.Error("Add() should assign an ID")
+	}
+	if w.Dish != "Chicken Tikka Masala" {
+		t.Errorf("Add() dish = %q, want trimmed", w.Dish)
+	}
+	if w.Days != DefaultDays {
+		t.Errorf("Add() days = %d, want %d", w.Days, DefaultDays)
+	}
+
+	if _, err := store.Add(Watch{Dish: "Pho", Days: 100}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	watches := store.List()
+	if len(watches) != 2 {
+		t.Fatalf("List() returned %d watches, want 2", len(watches))
+	}
+	if watches[1].Days != MaxDays {
+		t.Errorf("Days = %d, want capped at %d", watches[1].Days, MaxDays)
+	}
+
+	if !store.Remove(w.ID) {
+		t.Error("Remove() should report existing watch")
+	}
+	if store.Remove(w.ID) {
+		t.Error("Remove() should report missing watch")
+	}
+	if len(store.List()) != 1 {
+		t.Error("List() should have one watch after removal")
+	}
+}
+
+func TestStore_AddNormalizesNicknames(t *testing.T) {
+	store := NewStore()
+
+	w, err := store.Add(Watch{Dish: "Pho", Locations: []string{"FloMo", "lakeside"}, MealTypes: []string{"supper"}})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if want := []string{"Florence Moore Dining", "Lakeside Dining"}; !reflect.DeepEqual(w.Locations, want) {
+		t.Errorf("Locations = %v, want %v", w.Locations, want)
+	}
+	if want := []string{"Dinner"}; !reflect.DeepEqual(w.MealTypes, want) {
+		t.Errorf("MealTypes = %v, want %v", w.MealTypes, want)
+	}
+}
+
+func TestStore_AddValidation(t *testing.T) {
+	store := NewStore()
+
+	tests := []struct {
+		name  string
+		watch Watch
+	}{
+		{"empty dish", Watch{Dish: "   "}},
+		{"invalid location", Watch{Dish: "Pho", Locations: []string{"Nowhere"}}},
+		{"invalid meal type", Watch{Dish: "Pho", MealTypes: []string{"Elevenses"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Add(tt.watch); err == nil {
+				t.Error("Add() should return error")
+			}
+		})
+	}
+}
+
+func TestWatch_Matches(t *testing.T) {
+	w := Watch{Dish: "tikka masala"}
+	if !w.Matches("Chicken Tikka Masala") {
+		t.Error("Matches() should be case-insensitive substring match")
+	}
+	if w.Matches("Chicken Curry") {
+		t.Error("Matches() should not match unrelated item")
+	}
+}
+
+func TestScanner_ScanDeduplicates(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Add(Watch{Dish: "tikka masala", Days: 2}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	fetcher := &fakeFetcher{menus: map[string][]string{
+		"Branner Dining|1/15/2025|Dinner": {"Chicken Tikka Masala", "Rice"},
+		"Wilbur Dining|1/16/2025|Lunch":   {"Paneer Tikka Masala"},
+		"Stern Dining|1/17/2025|Lunch":    {"Chicken Tikka Masala"}, // outside window
+	}}
+	notifier := &recordingNotifier{}
+
+	scanner := NewScanner(store, fetcher, notifier)
+	scanner.Now = fixedClock(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+
+	alerts := scanner.Scan(context.Background())
+	if len(alerts) != 2 {
+		t.Fatalf("Scan() returned %d alerts, want 2: %v", len(alerts), alerts)
+	}
+	if len(notifier.alerts) != 2 {
+		t.Errorf("notifier received %d alerts, want 2", len(notifier.alerts))
+	}
+
+	// A second scan must not re-deliver the same alerts
+	if alerts := scanner.Scan(context.Background()); len(alerts) != 0 {
+		t.Errorf("second Scan() returned %d alerts, want 0", len(alerts))
+	}
+	if len(notifier.alerts) != 2 {
+		t.Errorf("notifier received %d alerts after rescan, want 2", len(notifier.alerts))
+	}
+}
+
+func TestScanner_ScanRespectsFilters(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Add(Watch{
+		Dish:      "pho",
+		Days:      1,
+		Locations: []string{"Branner Dining"},
+		MealTypes: []string{"Lunch"},
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	fetcher := &fakeFetcher{menus: map[string][]string{
+		"Branner Dining|1/15/2025|Lunch":  {"Beef Pho"},
+		"Branner Dining|1/15/2025|Dinner": {"Chicken Pho"},
+		"Wilbur Dining|1/15/2025|Lunch":   {"Veggie Pho"},
+	}}
+
+	scanner := NewScanner(store, fetcher)
+	scanner.Now = fixedClock(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+
+	alerts := scanner.Scan(context.Background())
+	if len(alerts) != 1 || alerts[0].Item != "Beef Pho" {
+		t.Errorf("Scan() = %v, want only Beef Pho", alerts)
+	}
+	if fetcher.calls != 1 {
+		t.Errorf("fetcher called %d times, want 1", fetcher.calls)
+	}
+}
+
+func TestScanner_ScanNoWatches(t *testing.T) {
+	fetcher := &fakeFetcher{}
+	scanner := NewScanner(NewStore(), fetcher)
+
+	if alerts := scanner.Scan(context.Background()); alerts != nil {
+		t.Errorf("Scan() = %v, want nil", alerts)
+	}
+	if fetcher.calls != 0 {
+		t.Error("Scan() should not fetch without watches")
+	}
+}
+
+func TestScanner_PrunesPastAlerts(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Add(Watch{Dish: "pho", Days: 1}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	fetcher := &fakeFetcher{menus: map[string][]string{
+		"Branner Dining|1/15/2025|Lunch": {"Beef Pho"},
+	}}
+
+	scanner := NewScanner(store, fetcher)
+	scanner.Now = fixedClock(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+	scanner.Scan(context.Background())
+
+	scanner.Now = fixedClock(time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC))
+	scanner.Scan(context.Background())
+
+	if len(scanner.sent) != 0 {
+		t.Errorf("sent has %d entries, want past alerts pruned", len(scanner.sent))
+	}
+}
+
+func TestScanner_TriggerMergesRequests(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Add(Watch{Dish: "pho", Days: 1, Locations: []string{"Branner Dining"}, MealTypes: []string{"Lunch"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	fetcher := &fakeFetcher{}
+	scanner := NewScanner(store, fetcher)
+	scanner.Now = fixedClock(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+
+	// Triggers before Run starts are merged into one scan after the first
+	for range 10 {
+		scanner.Trigger()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scanner.Run(ctx, time.Hour)
+		close(done)
+	}()
+	calls := func() int {
+		fetcher.mu.Lock()
+		defer fetcher.mu.Unlock()
+		return fetcher.calls
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for calls() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	if n := calls(); n != 2 {
+		t.Errorf("menus fetched %d times, want 2: the first scan and one triggered scan", n)
+	}
+}